@@ -0,0 +1,164 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/fusionmedialimited/backend-infra-libraries/v3/pkg/bootstrap"
+	"github.com/fusionmedialimited/backend-infra-libraries/v3/pkg/config"
+	"github.com/fusionmedialimited/backend-infra-libraries/v3/pkg/di"
+	"github.com/fusionmedialimited/backend-infra-libraries/v3/pkg/primitives"
+)
+
+const (
+	formatMarkdown = "markdown"
+	formatJSON     = "json"
+	secretSuffix   = "_FILE"
+)
+
+func main() {
+	var (
+		c           = di.New()
+		ctx, cancel = context.WithCancel(context.Background())
+		format      = flag.String("format", formatMarkdown, "output format: markdown or json")
+		checkEnv    = flag.Bool("check", false, "check current environment against known variables")
+	)
+	// dependencies are only registered, so the environment may be invalid, it's what is checked
+	bootstrap.Setup(ctx, c, "config", "docs", nil)
+	defer primitives.Must(func() (any, error) { cancel(); return nil, c.Release() }) //nolint:unparam // useless error
+
+	vars := config.DescribeRegistered()
+
+	flag.CommandLine.Usage = func() {
+		fmt.Println("./config-docs [-format markdown|json] [-check]")
+		flag.CommandLine.PrintDefaults()
+	}
+	flag.Parse()
+
+	if *checkEnv {
+		if problems := check(os.Stdout, vars, os.Environ()); problems > 0 {
+			fail(fmt.Sprintf("invalid environment: %d problems", problems), cancel, c)
+		}
+
+		return
+	}
+
+	var err error
+	switch *format {
+	case formatMarkdown:
+		err = writeMarkdown(os.Stdout, vars)
+	case formatJSON:
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		err = enc.Encode(vars)
+	default:
+		flag.CommandLine.Usage()
+		err = fmt.Errorf("unknown format %q", *format)
+	}
+
+	if err != nil {
+		fail(err.Error(), cancel, c)
+	}
+}
+
+// fail releases the container, since deferred calls don't run on exit.
+func fail(msg string, cancel context.CancelFunc, c *di.Container) {
+	_, _ = fmt.Fprintln(os.Stderr, msg)
+	cancel()
+	_ = c.Release()
+	os.Exit(1)
+}
+
+func writeMarkdown(w io.Writer, vars []config.Variable) error {
+	lines := []string{
+		"| Variable | Type | Default | Required | Description |",
+		"|---|---|---|---|---|",
+	}
+	for _, v := range vars {
+		def := v.Default
+		if def != "" {
+			def = "`" + def + "`"
+		}
+
+		required := ""
+		if v.Required {
+			required = "yes"
+		}
+
+		lines = append(lines, fmt.Sprintf("| `%s` | `%s` | %s | %s | %s |",
+			v.Name, v.Type, def, required, strings.ReplaceAll(v.Description, "|", `\|`)))
+	}
+
+	_, err := fmt.Fprintln(w, strings.Join(lines, "\n"))
+	return err
+}
+
+// check prints `+` for known variables, `?` for unknown ones of known groups and
+// `-` for missed required ones of groups in use, it returns number of problems.
+func check(w io.Writer, vars []config.Variable, environ []string) (problems int) {
+	var (
+		known    = make(map[string]config.Variable, len(vars))
+		prefixes = make(map[string]struct{})
+		inUse    = make(map[string]struct{})
+		envs     = make(map[string]struct{}, len(environ))
+	)
+	for _, kv := range environ {
+		name, _, _ := strings.Cut(kv, "=")
+		envs[name] = struct{}{}
+	}
+
+	for _, v := range vars {
+		known[v.Name] = v
+		if v.Secret {
+			known[v.Name+secretSuffix] = v
+		}
+
+		if v.Group == "" {
+			continue
+		}
+		prefixes[v.Group] = struct{}{}
+		if _, ok := envs[v.Name]; ok {
+			inUse[v.Group] = struct{}{}
+		}
+	}
+
+	names := make([]string, 0, len(envs))
+	for name := range envs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if _, ok := known[name]; ok {
+			_, _ = fmt.Fprintf(w, "+ %s\n", name)
+			continue
+		}
+
+		for prefix := range prefixes {
+			if strings.HasPrefix(name, prefix) {
+				_, _ = fmt.Fprintf(w, "? %s: unknown variable\n", name)
+				problems++
+
+				break
+			}
+		}
+	}
+
+	for _, v := range vars {
+		_, isUsed := inUse[v.Group]
+		_, isSet := envs[v.Name]
+		_, isFileSet := envs[v.Name+secretSuffix]
+		if v.Required && isUsed && !isSet && !(v.Secret && isFileSet) {
+			_, _ = fmt.Fprintf(w, "- %s: required variable is not set\n", v.Name)
+			problems++
+		}
+	}
+
+	return problems
+}
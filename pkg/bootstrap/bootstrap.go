@@ -12,6 +12,7 @@ import (
 
 	"github.com/fusionmedialimited/backend-infra-libraries/v3/pkg/config"
 	"github.com/fusionmedialimited/backend-infra-libraries/v3/pkg/di"
+	"github.com/fusionmedialimited/backend-infra-libraries/v3/pkg/featureflags"
 	"github.com/fusionmedialimited/backend-infra-libraries/v3/pkg/http/client"
 	"github.com/fusionmedialimited/backend-infra-libraries/v3/pkg/http/server"
 	"github.com/fusionmedialimited/backend-infra-libraries/v3/pkg/storage/mysql"
@@ -32,6 +33,7 @@ func Setup(
 	GracefulSetup(c) //nolint:contextcheck // "lazy" loaded context
 	client.Setup(c)  //nolint:contextcheck // "lazy" loaded context
 	server.Setup(ctx, c, spec)
-	mysql.Setup(c)    //nolint:contextcheck // "lazy" loaded context
-	postgres.Setup(c) //nolint:contextcheck // "lazy" loaded context
+	featureflags.Setup(c) //nolint:contextcheck // "lazy" loaded context
+	mysql.Setup(c)        //nolint:contextcheck // "lazy" loaded context
+	postgres.Setup(c)     //nolint:contextcheck // "lazy" loaded context
 }
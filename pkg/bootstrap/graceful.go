@@ -8,11 +8,14 @@ import (
 
 	"go.uber.org/zap"
 
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 
 	"github.com/fusionmedialimited/backend-infra-libraries/v3/pkg/config"
 	"github.com/fusionmedialimited/backend-infra-libraries/v3/pkg/di"
+	"github.com/fusionmedialimited/backend-infra-libraries/v3/pkg/http/client"
 	"github.com/fusionmedialimited/backend-infra-libraries/v3/pkg/http/server"
+	"github.com/fusionmedialimited/backend-infra-libraries/v3/pkg/primitives"
 )
 
 type (
@@ -39,6 +42,8 @@ func GracefulSetup(c *di.Container) {
 	di.Set(c, di.OptInit(func() (Introspection, error) {
 		var (
 			conf      = di.Get[config.Introspection](c)
+			faults    = di.Get[client.FaultConfig](c)
+			gatherer  = di.Get[prometheus.Gatherer](c)
 			logger    = di.Get[*zap.Logger](c).Sugar()
 			readiness = di.Get[*server.Readiness](c)
 			srv       = di.Get[*http.Server](c)
@@ -48,16 +53,43 @@ func GracefulSetup(c *di.Container) {
 			return func() error { return nil }, nil
 		}
 
+		var injector *client.FaultInjector
+		if faults.Enabled {
+			injector = di.Get[*client.FaultInjector](c)
+		}
+
+		named := config.NamedValues(c)
 		return func() (err error) {
 			const (
+				configURL    = "/debug/config"
+				faultsURL    = "/debug/faults"
 				metricsURL   = "/metrics"
 				pprofURL     = "/debug/pprof"
 				readinessURL = "/readiness"
 			)
+			logger.Debugw("Effective config", "config", config.Dump{Named: named, Configs: config.Resolved()})
+
 			logger.Infof("Serve pprof from %s%s", conf.Sock, pprofURL)
 
+			logger.Infof("Serve config from %s%s", conf.Sock, configURL)
+			http.HandleFunc(configURL, func(w http.ResponseWriter, _ *http.Request) {
+				data, err := primitives.MarshalJSON(config.Dump{Named: named, Configs: config.Resolved()})
+				if err != nil {
+					http.Error(w, err.Error(), http.StatusInternalServerError)
+					return
+				}
+
+				w.Header().Set("Content-Type", "application/json")
+				_, _ = w.Write(data)
+			})
+
+			if injector != nil {
+				logger.Warnf("Serve fault injection rules from %s%s", conf.Sock, faultsURL)
+				http.Handle(faultsURL, injector)
+			}
+
 			logger.Infof("Serve metrics from %s%s", conf.Sock, metricsURL)
-			http.Handle(metricsURL, promhttp.Handler())
+			http.Handle(metricsURL, promhttp.HandlerFor(gatherer, promhttp.HandlerOpts{}))
 
 			logger.Infof("Serve readiness probe from %s%s", conf.Sock, readinessURL)
 			http.Handle(readinessURL, readiness)
@@ -13,13 +13,24 @@ import (
 
 func LoggerSetup(c *di.Container) {
 	di.Set(c, di.OptInit(func() (*zap.Logger, error) {
-		conf := di.Get[config.Introspection](c)
+		var (
+			dynamic = di.Get[*config.Dynamic[config.Introspection]](c)
+			conf    = dynamic.Get()
+			level   = zap.NewAtomicLevelAt(zapcore.Level(conf.LogLevel))
+		)
 
-		logger, err := newLogger(conf.LogLevel)
+		logger, err := newLogger(level)
 		if err != nil {
 			return nil, err
 		}
 
+		dynamic.Subscribe(func(conf config.Introspection) {
+			level.SetLevel(zapcore.Level(conf.LogLevel))
+		})
+		dynamic.OnError(func(err error) {
+			logger.Warn("Dynamic config is not reloaded", zap.Error(err))
+		})
+
 		name := di.GetNamed[string](c, config.AppName)
 		if envName := conf.Name; envName != "" {
 			name = envName
@@ -38,16 +49,13 @@ func LoggerSetup(c *di.Container) {
 	}))
 }
 
-func newLogger(lvl int8) (*zap.Logger, error) {
-	level := zapcore.Level(lvl)
-
-	if level == zapcore.DebugLevel {
-		logger, err := zap.NewDevelopment()
-		return logger, err
+func newLogger(level zap.AtomicLevel) (*zap.Logger, error) {
+	config := zap.NewProductionConfig()
+	if level.Level() == zapcore.DebugLevel {
+		config = zap.NewDevelopmentConfig()
 	}
 
-	config := zap.NewProductionConfig()
-	config.Level = zap.NewAtomicLevelAt(level)
+	config.Level = level
 
 	return config.Build()
 }
@@ -1,7 +1,9 @@
 package config
 
 import (
+	"errors"
 	"os"
+	"reflect"
 	"strings"
 	"time"
 
@@ -12,6 +14,8 @@ import (
 
 var version = "dev"
 
+type lookupFunc func(key string) (string, bool)
+
 const (
 	AppName      = "$app_name"
 	AppNamespace = "$app_namespace"
@@ -38,15 +42,54 @@ func Setup(c *di.Container, namespace, subsystem string) {
 		return namespace + "_" + subsystem, nil
 	}))
 	di.SetNamed(c, Hostname, di.OptInit(os.Hostname))
-	di.Set(c, di.OptInit(func() (conf Introspection, _ error) {
-		return conf, env.Parse(&conf) //nolint:gocritic // it's correct evaluation order
+
+	Register(DynamicSource{}, "")
+	di.Set(c, di.OptInit(func() (conf DynamicSource, _ error) {
+		err := Parse(&conf, "")
+		return conf, err
+	}))
+
+	SetupDynamic[Introspection](c, "introspection", "")
+	di.Set(c, di.OptInit(func() (Introspection, error) {
+		return di.Get[*Dynamic[Introspection]](c).Get(), nil
 	}))
 }
 
+// Parse works as env.Parse, fields tagged by `secret:"true"` may be read from `<ENV>_FILE`
+// and their defaults are refused in production. Parsed config is checked by Validate.
+func Parse(v any, prefix string) error {
+	return ParseEnv(v, prefix, nil)
+}
+
+// ParseEnv works as Parse with variables of the environ, if it's not nil.
+func ParseEnv(v any, prefix string, environ map[string]string) error {
+	if err := env.Parse(v, env.Options{Prefix: prefix, Environment: environ}); err != nil {
+		return err
+	}
+
+	var lookup lookupFunc = os.LookupEnv
+	if environ != nil {
+		lookup = func(key string) (string, bool) {
+			val, ok := environ[key]
+			return val, ok
+		}
+	}
+
+	err := errors.Join(
+		resolveSecrets(reflect.ValueOf(v).Elem(), prefix, lookup),
+		Validate(v, prefix),
+	)
+	if err == nil {
+		record(v, prefix, lookup)
+	}
+
+	return err
+}
+
 type Introspection struct {
-	Name        string        `env:"INTROSPECTION_NAME"       envDefault:""`
-	Sock        string        `env:"INTROSPECTION_SOCK"       envDefault:"0.0.0.0:1984"`
-	Timeout     time.Duration `env:"INTROSPECTION_TIMEOUT"    envDefault:"5s"`
-	ShutdownNum int           `env:"INTROSPECTION_STOP_COUNT" envDefault:"2"`
-	LogLevel    int8          `env:"LOG_LEVEL"                envDefault:"0"` // info
+	Name        string        `env:"INTROSPECTION_NAME"       envDefault:"" desc:"Overrides application name in logs"`
+	Sock        string        `env:"INTROSPECTION_SOCK"       envDefault:"0.0.0.0:1984" desc:"Address of metrics, pprof and readiness server, empty disables it"`
+	Timeout     time.Duration `env:"INTROSPECTION_TIMEOUT"    envDefault:"5s" validate:"min=0s" desc:"Graceful shutdown timeout"`
+	ShutdownNum int           `env:"INTROSPECTION_STOP_COUNT" envDefault:"2"  validate:"min=0" desc:"Readiness probes to fail before shutdown"`
+	LogLevel    int8          `env:"LOG_LEVEL"                envDefault:"0"  validate:"min=-1,max=5" desc:"Zap log level, -1 is debug"` // info
 }
@@ -0,0 +1,142 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+	"sync"
+)
+
+type (
+	Variable struct {
+		Name   string `json:"name"`
+		Prefix string `json:"-"`
+		// Group is a common prefix of neighbour variables, like `HTTP_SERVER_`.
+		Group       string `json:"-"`
+		Type        string `json:"type"`
+		Default     string `json:"default,omitempty"`
+		Required    bool   `json:"required"`
+		Secret      bool   `json:"secret,omitempty"`
+		Description string `json:"description,omitempty"`
+		Value       string `json:"value,omitempty"`
+		Source      Source `json:"source,omitempty"`
+
+		hasDefault bool
+	}
+
+	registration struct {
+		prefix string
+		typ    reflect.Type
+	}
+)
+
+var registry struct {
+	sync.Mutex
+	items    []registration
+	resolved []Snapshot
+}
+
+// Register adds config struct to known configs for documentation and diagnostics.
+func Register(v any, prefix string) {
+	typ := reflect.Indirect(reflect.ValueOf(v)).Type()
+
+	registry.Lock()
+	defer registry.Unlock()
+
+	for _, item := range registry.items {
+		if item.prefix == prefix && item.typ == typ {
+			return
+		}
+	}
+
+	registry.items = append(registry.items, registration{prefix: prefix, typ: typ})
+}
+
+func DescribeRegistered() []Variable {
+	registry.Lock()
+	defer registry.Unlock()
+
+	vars := make([]Variable, 0, len(registry.items))
+	for _, item := range registry.items {
+		vars = append(vars, describe(item.typ, item.prefix)...)
+	}
+
+	return vars
+}
+
+// Describe lists env variables of config struct, description is taken from `desc` tag.
+func Describe(v any, prefix string) []Variable {
+	return describe(reflect.Indirect(reflect.ValueOf(v)).Type(), prefix)
+}
+
+func describe(typ reflect.Type, prefix string) []Variable {
+	vars := make([]Variable, 0, typ.NumField())
+	for i := 0; i < typ.NumField(); i++ {
+		if v, ok := describeField(typ.Field(i), prefix); ok {
+			vars = append(vars, v)
+		}
+	}
+
+	clusters := make(map[string][]Variable)
+	for _, v := range vars {
+		word := firstWord(v.Name, prefix)
+		clusters[word] = append(clusters[word], v)
+	}
+
+	for i := range vars {
+		vars[i].Group = prefix
+		if cluster := clusters[firstWord(vars[i].Name, prefix)]; len(cluster) > 1 {
+			vars[i].Group = commonPrefix(cluster)
+		}
+	}
+
+	return vars
+}
+
+func firstWord(name, prefix string) string {
+	word, _, _ := strings.Cut(strings.TrimPrefix(name, prefix), "_")
+	return word
+}
+
+func commonPrefix(vars []Variable) string {
+	if len(vars) == 0 {
+		return ""
+	}
+
+	prefix := vars[0].Name
+	for _, v := range vars[1:] {
+		for !strings.HasPrefix(v.Name, prefix) {
+			prefix = prefix[:len(prefix)-1]
+		}
+	}
+
+	return prefix[:strings.LastIndex(prefix, "_")+1]
+}
+
+func describeField(field reflect.StructField, prefix string) (Variable, bool) {
+	name, opts, _ := strings.Cut(field.Tag.Get("env"), ",")
+	if name == "" || name == "-" {
+		return Variable{}, false
+	}
+
+	def, hasDefault := field.Tag.Lookup("envDefault")
+	return Variable{
+		Name:        prefix + name,
+		Prefix:      prefix,
+		Type:        field.Type.String(),
+		Default:     def,
+		hasDefault:  hasDefault,
+		Required:    hasOption(opts, "required") || !hasDefault && hasOption(field.Tag.Get("validate"), "required"),
+		Secret:      isSecret(field),
+		Description: field.Tag.Get("desc"),
+	}, true
+}
+
+func hasOption(opts, option string) bool {
+	for _, opt := range strings.Split(opts, ",") {
+		if strings.TrimSpace(opt) == option {
+			return true
+		}
+	}
+
+	return false
+}
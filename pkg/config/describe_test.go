@@ -0,0 +1,48 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDescribe(t *testing.T) {
+	conf := struct {
+		Name     string `env:"NAME"     envDefault:"app" desc:"Application name"`
+		Password string `env:"PASSWORD" secret:"true"`
+		Database string `env:"DATABASE" validate:"required"`
+		URL      string `env:"URL,required"`
+		Skipped  string `env:"-"`
+		Internal string
+	}{}
+
+	want := []Variable{
+		{Name: "TEST_NAME", Prefix: "TEST_", Group: "TEST_", Type: "string", Default: "app", Description: "Application name", hasDefault: true},
+		{Name: "TEST_PASSWORD", Prefix: "TEST_", Group: "TEST_", Type: "string", Secret: true},
+		{Name: "TEST_DATABASE", Prefix: "TEST_", Group: "TEST_", Type: "string", Required: true},
+		{Name: "TEST_URL", Prefix: "TEST_", Group: "TEST_", Type: "string", Required: true},
+	}
+	if got := Describe(&conf, "TEST_"); !reflect.DeepEqual(got, want) {
+		t.Errorf("Describe() = %+v, want %+v", got, want)
+	}
+}
+
+func TestDescribeGroups(t *testing.T) {
+	want := map[string]string{
+		"INTROSPECTION_NAME":       "INTROSPECTION_",
+		"INTROSPECTION_SOCK":       "INTROSPECTION_",
+		"INTROSPECTION_TIMEOUT":    "INTROSPECTION_",
+		"INTROSPECTION_STOP_COUNT": "INTROSPECTION_",
+		"LOG_LEVEL":                "",
+	}
+	for _, v := range Describe(Introspection{}, "") {
+		if v.Group != want[v.Name] {
+			t.Errorf("%s group = %q, want %q", v.Name, v.Group, want[v.Name])
+		}
+	}
+
+	for _, v := range Describe(Introspection{}, "APP_") {
+		if want := "APP_INTROSPECTION_"; v.Name != "APP_LOG_LEVEL" && v.Group != want {
+			t.Errorf("%s group = %q, want %q", v.Name, v.Group, want)
+		}
+	}
+}
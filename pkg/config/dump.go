@@ -0,0 +1,158 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+
+	"github.com/fusionmedialimited/backend-infra-libraries/v3/pkg/di"
+)
+
+const (
+	SourceEnv     Source = "env"
+	SourceFile    Source = "file"
+	SourceDefault Source = "default"
+	SourceUnset   Source = "unset"
+)
+
+type (
+	Source string
+
+	Snapshot struct {
+		Type      string     `json:"type"`
+		Prefix    string     `json:"prefix,omitempty"`
+		Parsed    bool       `json:"parsed"`
+		Variables []Variable `json:"variables"`
+	}
+
+	Dump struct {
+		Named   map[string]string `json:"named"`
+		Configs []Snapshot        `json:"configs"`
+	}
+)
+
+func NamedValues(c *di.Container) map[string]string {
+	named := make(map[string]string)
+	for _, name := range []string{AppName, AppNamespace, AppSubsystem, AppVersion, Hostname} {
+		named[name] = di.GetNamed[string](c, name)
+	}
+
+	return named
+}
+
+// Resolved returns snapshots of parsed configs followed by registered but not parsed ones.
+func Resolved() []Snapshot {
+	registry.Lock()
+	defer registry.Unlock()
+
+	snapshots := append([]Snapshot(nil), registry.resolved...)
+	for _, item := range registry.items {
+		if !isResolved(item.typ.String(), item.prefix) {
+			snapshots = append(snapshots, snapshotEnv(item, os.LookupEnv))
+		}
+	}
+
+	return snapshots
+}
+
+func isResolved(typ, prefix string) bool {
+	for _, item := range registry.resolved {
+		if item.Type == typ && item.Prefix == prefix {
+			return true
+		}
+	}
+
+	return false
+}
+
+func snapshotEnv(item registration, lookup lookupFunc) Snapshot {
+	vars := describe(item.typ, item.prefix)
+	for i, v := range vars {
+		vars[i].Source = source(v, lookup)
+		switch vars[i].Source {
+		case SourceEnv:
+			vars[i].Value, _ = lookup(v.Name)
+		case SourceDefault:
+			vars[i].Value = v.Default
+		}
+
+		if v.Secret && vars[i].Value != "" || vars[i].Source == SourceFile {
+			vars[i].Value = secretMask
+		}
+	}
+
+	return Snapshot{Type: item.typ.String(), Prefix: item.prefix, Variables: vars}
+}
+
+func record(v any, prefix string, lookup lookupFunc) {
+	var (
+		val      = reflect.Indirect(reflect.ValueOf(v))
+		snapshot = Snapshot{
+			Type:      val.Type().String(),
+			Prefix:    prefix,
+			Parsed:    true,
+			Variables: make([]Variable, 0, val.NumField()),
+		}
+	)
+	for i := 0; i < val.NumField(); i++ {
+		fieldType := val.Type().Field(i)
+		v, ok := describeField(fieldType, prefix)
+		if !ok {
+			continue
+		}
+
+		v.Value, v.Source = formatValue(val.Field(i), fieldType), source(v, lookup)
+		if v.Secret && v.Value != "" {
+			v.Value = secretMask
+		}
+
+		snapshot.Variables = append(snapshot.Variables, v)
+	}
+
+	registry.Lock()
+	defer registry.Unlock()
+
+	for i, item := range registry.resolved {
+		if item.Type == snapshot.Type && item.Prefix == prefix {
+			registry.resolved[i] = snapshot
+			return
+		}
+	}
+
+	registry.resolved = append(registry.resolved, snapshot)
+}
+
+func source(v Variable, lookup lookupFunc) Source {
+	if _, ok := lookup(v.Name + secretFileSuffix); ok && v.Secret {
+		return SourceFile
+	}
+
+	if _, ok := lookup(v.Name); ok {
+		return SourceEnv
+	}
+
+	if v.hasDefault {
+		return SourceDefault
+	}
+
+	return SourceUnset
+}
+
+func formatValue(field reflect.Value, fieldType reflect.StructField) string {
+	if field.Kind() == reflect.Slice {
+		sep, ok := fieldType.Tag.Lookup("envSeparator")
+		if !ok {
+			sep = ","
+		}
+
+		items := make([]string, 0, field.Len())
+		for i := 0; i < field.Len(); i++ {
+			items = append(items, fmt.Sprint(field.Index(i).Interface()))
+		}
+
+		return strings.Join(items, sep)
+	}
+
+	return fmt.Sprint(field.Interface())
+}
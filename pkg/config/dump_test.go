@@ -0,0 +1,74 @@
+package config
+
+import (
+	"testing"
+)
+
+func TestParseRecord(t *testing.T) {
+	t.Setenv("DUMP_HOST", "localhost")
+	t.Setenv("DUMP_PASSWORD", "secret")
+
+	conf := struct {
+		Host     string   `env:"HOST"`
+		Port     int      `env:"PORT"     envDefault:"80"`
+		Password string   `env:"PASSWORD" secret:"true"`
+		Options  []string `env:"OPTIONS"  envDefault:"a=1&b=2" envSeparator:"&"`
+		User     string   `env:"USER"`
+		Region   string   `env:"REGION"   envDefault:""`
+	}{}
+	if err := Parse(&conf, "DUMP_"); err != nil {
+		t.Fatal(err)
+	}
+
+	var snapshot *Snapshot
+	for _, item := range Resolved() {
+		item := item // pin
+		if item.Prefix == "DUMP_" {
+			snapshot = &item
+		}
+	}
+	if snapshot == nil {
+		t.Fatalf("Resolved() has no DUMP_ config")
+	}
+
+	want := map[string][2]string{
+		"DUMP_HOST":     {"localhost", string(SourceEnv)},
+		"DUMP_PORT":     {"80", string(SourceDefault)},
+		"DUMP_PASSWORD": {secretMask, string(SourceEnv)},
+		"DUMP_OPTIONS":  {"a=1&b=2", string(SourceDefault)},
+		"DUMP_USER":     {"", string(SourceUnset)},
+		"DUMP_REGION":   {"", string(SourceDefault)},
+	}
+	for _, v := range snapshot.Variables {
+		if got := [2]string{v.Value, string(v.Source)}; got != want[v.Name] {
+			t.Errorf("%s = %v, want %v", v.Name, got, want[v.Name])
+		}
+	}
+}
+
+func TestResolvedRegistered(t *testing.T) {
+	t.Setenv("REGISTERED_TOKEN", "secret")
+
+	type registeredConfig struct {
+		Token string `env:"TOKEN" secret:"true"`
+		Host  string `env:"HOST"  envDefault:"localhost"`
+	}
+	Register(registeredConfig{}, "REGISTERED_")
+
+	for _, item := range Resolved() {
+		if item.Prefix != "REGISTERED_" {
+			continue
+		}
+
+		want := []string{secretMask, "localhost"}
+		for i, v := range item.Variables {
+			if item.Parsed || v.Value != want[i] {
+				t.Errorf("%s = %q, parsed %v, want %q", v.Name, v.Value, item.Parsed, want[i])
+			}
+		}
+
+		return
+	}
+
+	t.Error("Resolved() has no registered config")
+}
@@ -0,0 +1,264 @@
+package config
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/fusionmedialimited/backend-infra-libraries/v3/pkg/di"
+	"github.com/fusionmedialimited/backend-infra-libraries/v3/pkg/primitives"
+)
+
+type (
+	// Dynamic is a config value reloaded at runtime.
+	Dynamic[T any] struct {
+		name string
+		load func() (T, error)
+		val  atomic.Pointer[T]
+
+		mu      sync.Mutex
+		hash    string
+		subs    []func(T)
+		onError []func(error)
+		metrics dynamicMetrics
+	}
+
+	DynamicSource struct {
+		File    string        `env:"CONFIG_DYNAMIC_FILE"                         desc:"Env file with dynamic settings, it overrides process env and is watched for changes"`
+		Refresh time.Duration `env:"CONFIG_DYNAMIC_REFRESH" envDefault:"10s" validate:"min=1s" desc:"Poll period of the dynamic settings file"`
+	}
+
+	dynamicMetrics struct {
+		version prometheus.Gauge
+		info    *prometheus.GaugeVec
+		errors  prometheus.Counter
+	}
+)
+
+// SetupDynamic registers *Dynamic[T] reloaded on change of DynamicSource file and SIGHUP.
+func SetupDynamic[T any](c *di.Container, name, prefix string) {
+	var conf T
+	Register(&conf, prefix)
+
+	di.Set(c, di.OptInit(func() (*Dynamic[T], error) {
+		var (
+			ctx  = di.Get[context.Context](c)
+			src  = di.Get[DynamicSource](c)
+			load = FromEnv[T](prefix)
+		)
+		if src.File != "" {
+			load = FromEnvFile[T](src.File, prefix)
+		}
+
+		dynamic, err := NewDynamic(name, load, prometheus.DefaultRegisterer)
+		if err != nil {
+			return nil, err
+		}
+
+		if src.File != "" {
+			go dynamic.WatchFile(ctx, src.File, src.Refresh)
+		}
+		go dynamic.WatchSignal(ctx, syscall.SIGHUP)
+
+		return dynamic, nil
+	}))
+}
+
+func FromEnv[T any](prefix string) func() (T, error) {
+	return func() (conf T, _ error) {
+		err := Parse(&conf, prefix)
+		return conf, err
+	}
+}
+
+// FromEnvFile loads config from the process env overridden by `KEY=VALUE` lines of the file.
+func FromEnvFile[T any](path, prefix string) func() (T, error) {
+	return func() (conf T, _ error) {
+		environ, err := readEnvFile(path)
+		if err != nil {
+			return conf, err
+		}
+
+		err = ParseEnv(&conf, prefix, environ)
+		return conf, err
+	}
+}
+
+func NewDynamic[T any](name string, load func() (T, error), registerer prometheus.Registerer) (*Dynamic[T], error) {
+	d := &Dynamic[T]{name: name, load: load, metrics: newDynamicMetrics(name)}
+	if registerer != nil {
+		for _, collector := range []prometheus.Collector{d.metrics.version, d.metrics.info, d.metrics.errors} {
+			if err := registerer.Register(collector); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if _, err := d.Reload(); err != nil {
+		return nil, err
+	}
+
+	return d, nil
+}
+
+func (d *Dynamic[T]) Name() string {
+	return d.name
+}
+
+func (d *Dynamic[T]) Get() T {
+	return *d.val.Load()
+}
+
+func (d *Dynamic[T]) Subscribe(fn func(T)) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.subs = append(d.subs, fn)
+}
+
+func (d *Dynamic[T]) OnError(fn func(error)) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.onError = append(d.onError, fn)
+}
+
+func (d *Dynamic[T]) Reload() (changed bool, err error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	val, hash, err := d.loadHashed()
+	if err != nil {
+		err = fmt.Errorf("load %s: %w", d.name, err)
+		d.metrics.errors.Inc()
+		for _, fn := range d.onError {
+			fn(err)
+		}
+
+		return false, err
+	}
+
+	if hash == d.hash {
+		return false, nil
+	}
+
+	d.val.Store(&val)
+	d.hash = hash
+	d.metrics.version.Inc()
+	d.metrics.info.Reset()
+	d.metrics.info.WithLabelValues(hash).Set(1)
+
+	for _, fn := range d.subs {
+		fn(val)
+	}
+
+	return true, nil
+}
+
+func (d *Dynamic[T]) WatchFile(ctx context.Context, path string, period time.Duration) {
+	_ = primitives.WatchFile(ctx, path, period, func(_ []byte, err error) {
+		if err != nil {
+			d.metrics.errors.Inc()
+			return
+		}
+
+		_, _ = d.Reload()
+	})
+}
+
+func (d *Dynamic[T]) WatchSignal(ctx context.Context, signals ...os.Signal) {
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, signals...)
+	defer signal.Stop(ch)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ch:
+			_, _ = d.Reload()
+		}
+	}
+}
+
+func (d *Dynamic[T]) loadHashed() (val T, hash string, err error) {
+	if val, err = d.load(); err != nil {
+		return val, "", err
+	}
+
+	data, err := primitives.MarshalJSON(val)
+	if err != nil {
+		return val, "", err
+	}
+
+	sum := sha256.Sum256(data)
+	return val, hex.EncodeToString(sum[:8]), nil
+}
+
+func newDynamicMetrics(name string) dynamicMetrics {
+	const namespace, subsystem = "config", "dynamic"
+	labels := prometheus.Labels{"name": name}
+
+	return dynamicMetrics{
+		version: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace:   namespace,
+			Subsystem:   subsystem,
+			Name:        "version",
+			Help:        "A number of applied values of the dynamic config.",
+			ConstLabels: labels,
+		}),
+		info: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace:   namespace,
+			Subsystem:   subsystem,
+			Name:        "info",
+			Help:        "A hash of the current value of the dynamic config.",
+			ConstLabels: labels,
+		}, []string{"hash"}),
+		errors: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace:   namespace,
+			Subsystem:   subsystem,
+			Name:        "reload_errors_total",
+			Help:        "A counter of failed reloads of the dynamic config.",
+			ConstLabels: labels,
+		}),
+	}
+}
+
+func readEnvFile(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path) //nolint:gosec // path is provided by operator
+	if err != nil {
+		return nil, fmt.Errorf("read env file: %w", err)
+	}
+
+	environ := make(map[string]string)
+	for _, kv := range os.Environ() {
+		key, val, _ := strings.Cut(kv, "=")
+		environ[key] = val
+	}
+
+	for i, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		key, val, ok := strings.Cut(line, "=")
+		if !ok {
+			return nil, fmt.Errorf("env file %s:%d: no `=` in line", path, i+1)
+		}
+
+		environ[strings.TrimSpace(key)] = strings.Trim(strings.TrimSpace(val), `"'`)
+	}
+
+	return environ, nil
+}
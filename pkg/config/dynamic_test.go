@@ -0,0 +1,72 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+type dynamicConfig struct {
+	Ratio   float64 `env:"RATIO"   envDefault:"1" validate:"min=0,max=1"`
+	Enabled bool    `env:"ENABLED"`
+}
+
+func TestDynamic(t *testing.T) {
+	var (
+		path     = filepath.Join(t.TempDir(), "dynamic.env")
+		registry = prometheus.NewRegistry()
+		write    = func(content string) {
+			if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+				t.Fatal(err)
+			}
+		}
+	)
+	write("# initial\nDYN_RATIO=0.5\n")
+
+	dynamic, err := NewDynamic("test", FromEnvFile[dynamicConfig](path, "DYN_"), registry)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := dynamic.Get(); got.Ratio != 0.5 || got.Enabled {
+		t.Fatalf("Get() = %+v", got)
+	}
+
+	var notified []dynamicConfig
+	dynamic.Subscribe(func(conf dynamicConfig) { notified = append(notified, conf) })
+
+	write("DYN_RATIO=0.5\n")
+	if changed, err := dynamic.Reload(); changed || err != nil {
+		t.Errorf("Reload() = %v, %v, want no change", changed, err)
+	}
+
+	write("DYN_RATIO=0.1\nDYN_ENABLED='true'\n")
+	if changed, err := dynamic.Reload(); !changed || err != nil {
+		t.Errorf("Reload() = %v, %v, want change", changed, err)
+	}
+	if len(notified) != 1 || notified[0] != (dynamicConfig{Ratio: 0.1, Enabled: true}) {
+		t.Errorf("notified = %+v", notified)
+	}
+
+	write("DYN_RATIO=2\n")
+	if _, err := dynamic.Reload(); err == nil {
+		t.Errorf("Reload() of invalid value returns no error")
+	}
+
+	var fieldErr *FieldError
+	if _, err := dynamic.Reload(); !errors.As(err, &fieldErr) || fieldErr.Env != "DYN_RATIO" {
+		t.Errorf("Reload() = %v, want DYN_RATIO error", err)
+	}
+	if got := dynamic.Get(); got.Ratio != 0.1 {
+		t.Errorf("Get() = %+v, want the last valid value", got)
+	}
+	if got := testutil.ToFloat64(dynamic.metrics.version); got != 2 {
+		t.Errorf("version = %v, want 2", got)
+	}
+	if got := testutil.ToFloat64(dynamic.metrics.errors); got != 2 {
+		t.Errorf("errors = %v, want 2", got)
+	}
+}
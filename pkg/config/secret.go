@@ -0,0 +1,110 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+)
+
+const (
+	EnvAppEnv     = "APP_ENV"
+	AppEnvProduct = "production"
+
+	secretFileSuffix = "_FILE"
+	secretMask       = "***"
+)
+
+var (
+	ErrInsecureDefault = errors.New("insecure default value")
+	ErrAmbiguousSecret = errors.New("both value and file are set")
+	ErrNotStruct       = errors.New("not a struct")
+)
+
+func IsProduction() bool {
+	return isProduction(os.LookupEnv)
+}
+
+func isProduction(lookup lookupFunc) bool {
+	env, _ := lookup(EnvAppEnv)
+	return env == AppEnvProduct
+}
+
+// Redacted returns a copy of config struct with masked secrets.
+func Redacted[T any](conf T) (T, error) {
+	typ := reflect.TypeOf(conf)
+	if typ == nil || typ.Kind() != reflect.Struct {
+		return conf, fmt.Errorf("redact %T: %w", conf, ErrNotStruct)
+	}
+
+	val := reflect.New(typ).Elem()
+	val.Set(reflect.ValueOf(conf))
+
+	for i := 0; i < val.NumField(); i++ {
+		field, fieldType := val.Field(i), val.Type().Field(i)
+		if isSecret(fieldType) && field.String() != "" {
+			field.SetString(secretMask)
+		}
+	}
+
+	return val.Interface().(T), nil //nolint:forcetypeassert // it's the same type
+}
+
+func resolveSecrets(val reflect.Value, prefix string, lookup lookupFunc) error {
+	var errs []error
+	for i := 0; i < val.NumField(); i++ {
+		fieldType := val.Type().Field(i)
+		if !isSecret(fieldType) {
+			continue
+		}
+
+		name := prefix + envName(fieldType)
+		if err := resolveSecret(val.Field(i), fieldType, name, lookup); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func resolveSecret(field reflect.Value, fieldType reflect.StructField, name string, lookup lookupFunc) error {
+	if path, ok := lookup(name + secretFileSuffix); ok && path != "" {
+		if _, ok := lookup(name); ok {
+			return fmt.Errorf("%s and %s: %w", name, name+secretFileSuffix, ErrAmbiguousSecret)
+		}
+
+		secret, err := ReadSecretFile(path)
+		if err != nil {
+			return fmt.Errorf("%s: %w", name+secretFileSuffix, err)
+		}
+
+		field.SetString(secret)
+		return nil
+	}
+
+	if def, ok := fieldType.Tag.Lookup("envDefault"); ok && isProduction(lookup) && field.String() == def {
+		return fmt.Errorf("%s in %s environment: %w", name, AppEnvProduct, ErrInsecureDefault)
+	}
+
+	return nil
+}
+
+// ReadSecretFile reads a secret from the file without trailing new line.
+func ReadSecretFile(path string) (string, error) {
+	data, err := os.ReadFile(path) //nolint:gosec // path is provided by operator
+	if err != nil {
+		return "", fmt.Errorf("read secret file %s: %w", path, err)
+	}
+
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
+
+func isSecret(field reflect.StructField) bool {
+	return field.Type.Kind() == reflect.String && field.Tag.Get("secret") == "true"
+}
+
+func envName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("env"), ",")
+	return name
+}
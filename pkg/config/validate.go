@@ -0,0 +1,164 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type (
+	// Validator is implemented by configs with cross-field rules.
+	Validator interface {
+		Validate() error
+	}
+
+	FieldError struct {
+		Field string
+		Env   string
+		Err   error
+	}
+)
+
+var ErrUnknownRule = errors.New("unknown validation rule")
+
+func NewFieldError(field string, err error) *FieldError {
+	return &FieldError{Field: field, Err: err}
+}
+
+func (e *FieldError) Error() string {
+	name := e.Env
+	if name == "" {
+		name = e.Field
+	}
+
+	return name + ": " + e.Err.Error()
+}
+
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+// Validate checks fields by `validate` tag rules: required, min=<n> and max=<n>,
+// bounds of strings and slices are checked by length.
+func Validate(v any, prefix string) error {
+	var (
+		val  = reflect.Indirect(reflect.ValueOf(v))
+		errs []error
+	)
+	for i := 0; i < val.NumField(); i++ {
+		fieldType := val.Type().Field(i)
+		rules, ok := fieldType.Tag.Lookup("validate")
+		if !ok {
+			continue
+		}
+
+		for _, rule := range strings.Split(rules, ",") {
+			if err := checkRule(val.Field(i), rule, isSecret(fieldType)); err != nil {
+				errs = append(errs, &FieldError{Field: fieldType.Name, Env: prefix + envName(fieldType), Err: err})
+			}
+		}
+	}
+
+	if validator, ok := v.(Validator); ok {
+		if err := validator.Validate(); err != nil {
+			errs = append(errs, resolveEnv(err, val.Type(), prefix))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func resolveEnv(err error, typ reflect.Type, prefix string) error {
+	if joined, ok := err.(interface{ Unwrap() []error }); ok { //nolint:errorlint // it's a check of the join
+		for _, err := range joined.Unwrap() {
+			resolveEnv(err, typ, prefix)
+		}
+
+		return err
+	}
+
+	var fieldErr *FieldError
+	if errors.As(err, &fieldErr) && fieldErr.Env == "" {
+		if field, ok := typ.FieldByName(fieldErr.Field); ok {
+			fieldErr.Env = prefix + envName(field)
+		}
+	}
+
+	return err
+}
+
+func checkRule(field reflect.Value, rule string, secret bool) error {
+	name, arg, _ := strings.Cut(strings.TrimSpace(rule), "=")
+	switch name {
+	case "required":
+		if field.IsZero() {
+			return errors.New("is required")
+		}
+
+		return nil
+	case "min", "max":
+		val, bound, err := measure(field, arg)
+		if err != nil {
+			return err
+		}
+
+		subject := "must be"
+		if isLength(field) {
+			subject = "length must be"
+		}
+
+		switch {
+		case name == "min" && val < bound:
+			return fmt.Errorf("%s >= %s%s", subject, arg, got(field, secret))
+		case name == "max" && val > bound:
+			return fmt.Errorf("%s <= %s%s", subject, arg, got(field, secret))
+		default:
+			return nil
+		}
+	default:
+		return fmt.Errorf("%w %q", ErrUnknownRule, rule)
+	}
+}
+
+func measure(field reflect.Value, arg string) (val, bound float64, err error) {
+	if field.Type() == reflect.TypeOf(time.Duration(0)) {
+		d, err := time.ParseDuration(arg)
+		return float64(field.Int()), float64(d), err
+	}
+
+	if bound, err = strconv.ParseFloat(arg, 64); err != nil {
+		return 0, 0, fmt.Errorf("bound %q: %w", arg, err)
+	}
+
+	switch field.Kind() { //nolint:exhaustive // default covers all cases
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(field.Int()), bound, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(field.Uint()), bound, nil
+	case reflect.Float32, reflect.Float64:
+		return field.Float(), bound, nil
+	case reflect.String, reflect.Slice, reflect.Map:
+		return float64(field.Len()), bound, nil
+	default:
+		return 0, 0, fmt.Errorf("%w for %s", ErrUnknownRule, field.Type())
+	}
+}
+
+func isLength(field reflect.Value) bool {
+	kind := field.Kind()
+	return kind == reflect.String || kind == reflect.Slice || kind == reflect.Map
+}
+
+func got(field reflect.Value, secret bool) string {
+	switch {
+	case secret:
+		return ""
+	case isLength(field):
+		return fmt.Sprintf(", got %d", field.Len())
+	default:
+		return fmt.Sprintf(", got %v", field.Interface())
+	}
+}
@@ -0,0 +1,89 @@
+package config
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+type testConfig struct {
+	Name    string        `env:"NAME"    validate:"required"`
+	Ratio   float64       `env:"RATIO"   validate:"min=0,max=1"`
+	Timeout time.Duration `env:"TIMEOUT" validate:"min=1s"`
+	Tags    []string      `env:"TAGS"    validate:"max=2"`
+	Token   string        `env:"TOKEN"   validate:"min=8" secret:"true"`
+	Total   int           `env:"TOTAL"`
+	Idle    int           `env:"IDLE"`
+}
+
+func (c testConfig) Validate() error {
+	if c.Idle > c.Total {
+		return errors.Join(NewFieldError("Idle", errors.New("must be <= total")))
+	}
+
+	return nil
+}
+
+func TestValidate(t *testing.T) {
+	valid := testConfig{Name: "name", Ratio: 1, Timeout: time.Second, Token: "12345678"}
+	if err := Validate(&valid, "TEST_"); err != nil {
+		t.Errorf("Validate() = %v, want nil", err)
+	}
+
+	invalid := testConfig{Ratio: 2, Tags: []string{"a", "b", "c"}, Token: "secret", Idle: 1}
+	err := Validate(&invalid, "TEST_")
+	for _, want := range []string{
+		"TEST_NAME: is required",
+		"TEST_RATIO: must be <= 1, got 2",
+		"TEST_TIMEOUT: must be >= 1s, got 0s",
+		"TEST_TAGS: length must be <= 2, got 3",
+		"TEST_TOKEN: length must be >= 8\n",
+		"TEST_IDLE: must be <= total",
+	} {
+		if err == nil || !strings.Contains(err.Error()+"\n", want) {
+			t.Errorf("Validate() = %v, want %q", err, want)
+		}
+	}
+
+	var fieldErr *FieldError
+	if !errors.As(err, &fieldErr) {
+		t.Errorf("Validate() = %v, want FieldError", err)
+	}
+}
+
+func TestValidateUnknownRule(t *testing.T) {
+	conf := struct {
+		Name string `validate:"email"`
+	}{}
+	if err := Validate(&conf, ""); !errors.Is(err, ErrUnknownRule) {
+		t.Errorf("Validate() = %v, want %v", err, ErrUnknownRule)
+	}
+}
+
+func TestParseEnvProduction(t *testing.T) {
+	type secretConfig struct {
+		Password string `env:"PASSWORD" envDefault:"changeme" secret:"true"`
+	}
+
+	t.Setenv(EnvAppEnv, AppEnvProduct)
+	if err := ParseEnv(&secretConfig{}, "TEST_", map[string]string{}); err != nil {
+		t.Errorf("ParseEnv() = %v, want nil for non-production environ", err)
+	}
+
+	err := ParseEnv(&secretConfig{}, "TEST_", map[string]string{EnvAppEnv: AppEnvProduct})
+	if !errors.Is(err, ErrInsecureDefault) {
+		t.Errorf("ParseEnv() = %v, want %v", err, ErrInsecureDefault)
+	}
+}
+
+func TestRedacted(t *testing.T) {
+	conf, err := Redacted(testConfig{Name: "name", Token: "12345678"})
+	if err != nil || conf.Token != secretMask || conf.Name != "name" {
+		t.Errorf("Redacted() = %+v, %v", conf, err)
+	}
+
+	if _, err = Redacted(&testConfig{}); !errors.Is(err, ErrNotStruct) {
+		t.Errorf("Redacted() = %v, want %v", err, ErrNotStruct)
+	}
+}
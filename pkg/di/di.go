@@ -150,6 +150,10 @@ func OptMiddleware[T any](f func(T) (T, error)) func(*serviceImpl[T]) {
 	return func(s *serviceImpl[T]) {
 		init := s.init
 		s.init = func() (T, error) {
+			if init == nil {
+				return empty[T](), errors.New("middleware is set before the dependency itself")
+			}
+
 			val, err := init()
 			if err != nil {
 				return empty[T](), err
@@ -2,7 +2,9 @@ package di
 
 import (
 	"errors"
+	"fmt"
 	"strconv"
+	"strings"
 	"testing"
 )
 
@@ -86,6 +88,18 @@ func TestReuseWithMiddleware(t *testing.T) {
 	}
 }
 
+func TestMiddlewareWithoutInit(t *testing.T) {
+	c := New()
+	Set(c, OptMiddleware(func(i int) (int, error) { return i, nil }))
+
+	defer func() {
+		if r := recover(); r == nil || !strings.Contains(fmt.Sprint(r), "middleware is set before the dependency itself") {
+			t.Errorf("Unexpected panic: %v", r)
+		}
+	}()
+	Get[int](c)
+}
+
 func TestDeinit(t *testing.T) {
 	var (
 		c    = New()
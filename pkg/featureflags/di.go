@@ -0,0 +1,54 @@
+package featureflags
+
+import (
+	"context"
+	"syscall"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/fusionmedialimited/backend-infra-libraries/v3/pkg/config"
+	"github.com/fusionmedialimited/backend-infra-libraries/v3/pkg/di"
+)
+
+type Config struct {
+	File         string        `env:"FEATURE_FLAGS_FILE"                                  desc:"JSON file with feature flags, all flags are off if it's empty"`
+	Refresh      time.Duration `env:"FEATURE_FLAGS_REFRESH"       envDefault:"10s"         validate:"min=1s" desc:"Poll period of the feature flags file"`
+	UserHeader   string        `env:"FEATURE_FLAGS_USER_HEADER"   envDefault:"X-User-Id"   desc:"Request header with user ID"`
+	TenantHeader string        `env:"FEATURE_FLAGS_TENANT_HEADER" envDefault:"X-Tenant-Id" desc:"Request header with tenant"`
+}
+
+// Setup registers *Store and adds its middleware to the *echo.Echo, it must be called after server.Setup.
+func Setup(c *di.Container) {
+	config.Register(Config{}, "")
+	di.Set(c, di.OptInit(func() (conf Config, _ error) {
+		err := config.Parse(&conf, "")
+		return conf, err
+	}))
+	di.Set(c, di.OptInit(func() (*Store, error) {
+		var (
+			conf    = di.Get[Config](c)
+			ctx     = di.Get[context.Context](c)
+			name    = di.GetNamed[string](c, config.AppName)
+			current = func() Set { return nil }
+		)
+		if conf.File != "" {
+			dynamic, err := config.NewDynamic("featureflags", FromFile(conf.File), prometheus.DefaultRegisterer)
+			if err != nil {
+				return nil, err
+			}
+
+			go dynamic.WatchFile(ctx, conf.File, conf.Refresh)
+			go dynamic.WatchSignal(ctx, syscall.SIGHUP)
+			current = dynamic.Get
+		}
+
+		return NewStore(name, current, prometheus.DefaultRegisterer)
+	}))
+	di.Set(c, di.OptMiddleware(func(e *echo.Echo) (*echo.Echo, error) {
+		conf := di.Get[Config](c)
+		e.Use(NewMiddlewareFunc(di.Get[*Store](c), conf.UserHeader, conf.TenantHeader))
+		return e, nil
+	}))
+}
@@ -0,0 +1,210 @@
+package featureflags
+
+import (
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"os"
+	"sort"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/fusionmedialimited/backend-infra-libraries/v3/pkg/primitives"
+)
+
+const (
+	KindBool       Kind = "bool"
+	KindPercentage Kind = "percentage"
+	KindVariant    Kind = "variant"
+
+	VariantOn  = "on"
+	VariantOff = "off"
+
+	buckets = 10000 // percentage precision is 0.01%
+)
+
+var ErrInvalidFlag = errors.New("invalid flag")
+
+type (
+	Kind string
+
+	Flag struct {
+		Kind       Kind            `json:"kind"`
+		Enabled    bool            `json:"enabled"`
+		Percentage float64         `json:"percentage,omitempty"`
+		Variants   map[string]uint `json:"variants,omitempty"`
+		Tenants    []string        `json:"tenants,omitempty"`
+	}
+
+	Set map[string]Flag
+
+	Target struct {
+		UserID string
+		Tenant string
+	}
+
+	Store struct {
+		current     func() Set
+		evaluations *prometheus.CounterVec
+	}
+
+	// Evaluator has all flags off by zero value.
+	Evaluator struct {
+		store  *Store
+		target Target
+	}
+)
+
+func FromFile(path string) func() (Set, error) {
+	return func() (Set, error) {
+		data, err := os.ReadFile(path) //nolint:gosec // path is provided by operator
+		if err != nil {
+			return nil, fmt.Errorf("read flags: %w", err)
+		}
+
+		var set Set
+		if err = primitives.UnmarshalJSON(data, &set); err != nil {
+			return nil, err
+		}
+
+		return set, set.Validate()
+	}
+}
+
+func (s Set) Validate() error {
+	var errs []error
+	for name, flag := range s {
+		if err := flag.validate(); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (f *Flag) validate() error {
+	switch f.Kind {
+	case KindBool:
+		return nil
+	case KindPercentage:
+		if f.Percentage < 0 || f.Percentage > 100 {
+			return fmt.Errorf("%w: percentage must be in 0..100, got %v", ErrInvalidFlag, f.Percentage)
+		}
+
+		return nil
+	case KindVariant:
+		var total uint
+		for _, weight := range f.Variants {
+			total += weight
+		}
+
+		if total == 0 {
+			return fmt.Errorf("%w: variants must have positive total weight", ErrInvalidFlag)
+		}
+
+		return nil
+	default:
+		return fmt.Errorf("%w: unknown kind %q", ErrInvalidFlag, f.Kind)
+	}
+}
+
+func NewStore(name string, current func() Set, registerer prometheus.Registerer) (*Store, error) {
+	evaluations := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: name,
+		Subsystem: "featureflags",
+		Name:      "evaluations_total",
+		Help:      "A counter of flag evaluations by variant.",
+	}, []string{"flag", "variant"})
+
+	return &Store{current: current, evaluations: evaluations}, registerer.Register(evaluations)
+}
+
+func (s *Store) Evaluator(target Target) Evaluator {
+	return Evaluator{store: s, target: target}
+}
+
+func (e Evaluator) Enabled(name string) bool {
+	return e.Variant(name) != VariantOff
+}
+
+func (e Evaluator) Variant(name string) string {
+	if e.store == nil {
+		return VariantOff
+	}
+
+	variant := e.evaluate(name)
+	e.store.evaluations.WithLabelValues(name, variant).Inc()
+
+	return variant
+}
+
+func (e Evaluator) evaluate(name string) string {
+	flag, ok := e.store.current()[name]
+	if !ok || !flag.Enabled || !flag.allows(e.target.Tenant) {
+		return VariantOff
+	}
+
+	// anonymous users share one bucket, so they aren't rolled out
+	if e.target.UserID == "" && (flag.Kind == KindPercentage || flag.Kind == KindVariant) {
+		return VariantOff
+	}
+
+	switch flag.Kind {
+	case KindPercentage:
+		if float64(bucket(name, e.target.UserID)) < flag.Percentage*buckets/100 {
+			return VariantOn
+		}
+
+		return VariantOff
+	case KindVariant:
+		return flag.pick(bucket(name, e.target.UserID))
+	default:
+		return VariantOn
+	}
+}
+
+func (f *Flag) allows(tenant string) bool {
+	if len(f.Tenants) == 0 {
+		return true
+	}
+
+	for _, allowed := range f.Tenants {
+		if allowed == tenant {
+			return true
+		}
+	}
+
+	return false
+}
+
+// variants are sorted for stable assignment
+func (f *Flag) pick(bucket uint32) string {
+	var (
+		names = make([]string, 0, len(f.Variants))
+		total uint
+	)
+	for name, weight := range f.Variants {
+		names = append(names, name)
+		total += weight
+	}
+	sort.Strings(names)
+
+	var (
+		point = uint(bucket) * total / buckets
+		acc   uint
+	)
+	for _, name := range names {
+		if acc += f.Variants[name]; point < acc {
+			return name
+		}
+	}
+
+	return VariantOff
+}
+
+func bucket(flag, userID string) uint32 {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(flag + ":" + userID))
+
+	return h.Sum32() % buckets
+}
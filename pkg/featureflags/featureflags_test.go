@@ -0,0 +1,126 @@
+package featureflags
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func newStore(t *testing.T, set Set) *Store {
+	t.Helper()
+
+	store, err := NewStore("test", func() Set { return set }, prometheus.NewRegistry())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return store
+}
+
+func TestEvaluator(t *testing.T) {
+	store := newStore(t, Set{
+		"bool":     {Kind: KindBool, Enabled: true},
+		"disabled": {Kind: KindBool},
+		"tenant":   {Kind: KindBool, Enabled: true, Tenants: []string{"acme"}},
+		"half":     {Kind: KindPercentage, Enabled: true, Percentage: 50},
+		"ab":       {Kind: KindVariant, Enabled: true, Variants: map[string]uint{"a": 1, "b": 3}},
+	})
+
+	evaluator := store.Evaluator(Target{UserID: "1", Tenant: "acme"})
+	for name, want := range map[string]bool{"bool": true, "disabled": false, "tenant": true, "unknown": false} {
+		if got := evaluator.Enabled(name); got != want {
+			t.Errorf("Enabled(%q) = %v, want %v", name, got, want)
+		}
+	}
+
+	if store.Evaluator(Target{Tenant: "other"}).Enabled("tenant") {
+		t.Error("Enabled(tenant) = true for other tenant, want false")
+	}
+
+	anonymous := store.Evaluator(Target{Tenant: "acme"})
+	if anonymous.Enabled("half") || anonymous.Variant("ab") != VariantOff || !anonymous.Enabled("bool") {
+		t.Error("anonymous user is rolled out, want only bool flags")
+	}
+
+	var (
+		half     int
+		variants = make(map[string]int)
+	)
+	const users = 10000
+	for i := 0; i < users; i++ {
+		evaluator := store.Evaluator(Target{UserID: strconv.Itoa(i)})
+		if evaluator.Enabled("half") {
+			half++
+		}
+		variants[evaluator.Variant("ab")]++
+	}
+
+	if half < users*45/100 || half > users*55/100 {
+		t.Errorf("half is enabled for %d of %d users", half, users)
+	}
+
+	if variants["b"] < variants["a"]*2 || variants["a"]+variants["b"] != users {
+		t.Errorf("variants are %v, want a:b about 1:3", variants)
+	}
+
+	if got := testutil.ToFloat64(store.evaluations.WithLabelValues("half", VariantOn)); int(got) != half {
+		t.Errorf("evaluations of half = %v, want %d", got, half)
+	}
+}
+
+func TestEvaluatorStable(t *testing.T) {
+	store := newStore(t, Set{"ab": {Kind: KindVariant, Enabled: true, Variants: map[string]uint{"a": 1, "b": 1}}})
+	want := store.Evaluator(Target{UserID: "42"}).Variant("ab")
+	for i := 0; i < 10; i++ {
+		if got := store.Evaluator(Target{UserID: "42"}).Variant("ab"); got != want {
+			t.Fatalf("Variant() = %q, want %q", got, want)
+		}
+	}
+}
+
+func TestFromFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "flags.json")
+	if err := os.WriteFile(path, []byte(`{"a":{"kind":"percentage","enabled":true,"percentage":150}}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := FromFile(path)(); err == nil {
+		t.Error("FromFile() = nil error for invalid percentage")
+	}
+
+	if err := os.WriteFile(path, []byte(`{"a":{"kind":"bool","enabled":true}}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	set, err := FromFile(path)()
+	if err != nil || !set["a"].Enabled {
+		t.Errorf("FromFile() = %v, %v", set, err)
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	var (
+		e     = echo.New()
+		store = newStore(t, Set{"tenant": {Kind: KindBool, Enabled: true, Tenants: []string{"acme"}}})
+	)
+	e.Use(NewMiddlewareFunc(store, "X-User-Id", "X-Tenant-Id"))
+	e.GET("/", func(c echo.Context) error {
+		return c.String(http.StatusOK, FromContext(c.Request().Context()).Variant("tenant"))
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("X-Tenant-Id", "acme")
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	if rec.Body.String() != VariantOn {
+		t.Errorf("response = %q, want %q", rec.Body.String(), VariantOn)
+	}
+}
@@ -0,0 +1,34 @@
+package featureflags
+
+import (
+	"context"
+
+	"github.com/labstack/echo/v4"
+)
+
+type evaluatorKey struct{}
+
+// NewMiddlewareFunc puts Evaluator for the target from request headers into the request context.
+func NewMiddlewareFunc(store *Store, userHeader, tenantHeader string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+			evaluator := store.Evaluator(Target{
+				UserID: req.Header.Get(userHeader),
+				Tenant: req.Header.Get(tenantHeader),
+			})
+			c.SetRequest(req.WithContext(NewContext(req.Context(), evaluator)))
+
+			return next(c)
+		}
+	}
+}
+
+func NewContext(ctx context.Context, evaluator Evaluator) context.Context {
+	return context.WithValue(ctx, evaluatorKey{}, evaluator)
+}
+
+func FromContext(ctx context.Context) Evaluator {
+	evaluator, _ := ctx.Value(evaluatorKey{}).(Evaluator)
+	return evaluator
+}
@@ -0,0 +1,228 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	pph "github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const (
+	BreakerClosed BreakerState = iota
+	BreakerOpen
+	BreakerHalfOpen
+)
+
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+type (
+	BreakerState int
+
+	// BreakerPolicy zero fields are taken from DefaultBreakerPolicy.
+	BreakerPolicy struct {
+		Window           time.Duration
+		MinRequests      int
+		FailureRatio     float64
+		CoolDown         time.Duration
+		HalfOpenRequests int
+		PerService       bool
+		Failure          func(resp *http.Response, err error) bool
+	}
+
+	breaker struct {
+		policy BreakerPolicy
+		gauge  prometheus.Gauge
+
+		mu          sync.Mutex
+		state       BreakerState
+		windowStart time.Time
+		requests    int
+		failures    int
+		openedAt    time.Time
+		probes      int
+		successes   int
+	}
+)
+
+func DefaultBreakerPolicy() BreakerPolicy {
+	const (
+		defaultWindow           = time.Second * 10
+		defaultMinRequests      = 20
+		defaultFailureRatio     = 0.5
+		defaultCoolDown         = time.Second * 5
+		defaultHalfOpenRequests = 1
+	)
+
+	return BreakerPolicy{
+		Window:           defaultWindow,
+		MinRequests:      defaultMinRequests,
+		FailureRatio:     defaultFailureRatio,
+		CoolDown:         defaultCoolDown,
+		HalfOpenRequests: defaultHalfOpenRequests,
+		Failure:          DefaultFailure,
+	}
+}
+
+func (p BreakerPolicy) withDefaults() BreakerPolicy {
+	def := DefaultBreakerPolicy()
+	if p.Window <= 0 {
+		p.Window = def.Window
+	}
+	if p.MinRequests <= 0 {
+		p.MinRequests = def.MinRequests
+	}
+	if p.FailureRatio <= 0 {
+		p.FailureRatio = def.FailureRatio
+	}
+	if p.CoolDown <= 0 {
+		p.CoolDown = def.CoolDown
+	}
+	if p.HalfOpenRequests <= 0 {
+		p.HalfOpenRequests = def.HalfOpenRequests
+	}
+	if p.Failure == nil {
+		p.Failure = def.Failure
+	}
+
+	return p
+}
+
+// DefaultFailure treats transport errors and 5xx responses as failures, canceled requests aren't recorded.
+func DefaultFailure(resp *http.Response, err error) bool {
+	if err != nil {
+		return true
+	}
+
+	return resp.StatusCode >= http.StatusInternalServerError
+}
+
+func InstrumentRoundTripperBreaker(
+	policy BreakerPolicy,
+	service string,
+	gauge *prometheus.GaugeVec,
+	next http.RoundTripper,
+) pph.RoundTripperFunc {
+	policy = policy.withDefaults()
+
+	var (
+		mu       sync.Mutex
+		breakers = make(map[string]*breaker)
+	)
+	get := func(target string) *breaker {
+		mu.Lock()
+		defer mu.Unlock()
+
+		b, ok := breakers[target]
+		if !ok {
+			b = &breaker{policy: policy, gauge: gauge.WithLabelValues(target)}
+			b.gauge.Set(float64(BreakerClosed))
+			breakers[target] = b
+		}
+
+		return b
+	}
+
+	return func(r *http.Request) (*http.Response, error) {
+		target := r.URL.Host
+		if policy.PerService {
+			target = service
+		}
+
+		b := get(target)
+		if !b.allow(time.Now()) {
+			return nil, fmt.Errorf("%s: %w", target, ErrCircuitOpen)
+		}
+
+		resp, err := next.RoundTrip(r)
+		if err != nil && errors.Is(err, context.Canceled) {
+			b.release()
+			return resp, err
+		}
+		b.record(time.Now(), policy.Failure(resp, err))
+
+		return resp, err
+	}
+}
+
+func (b *breaker) allow(now time.Time) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case BreakerOpen:
+		if now.Sub(b.openedAt) < b.policy.CoolDown {
+			return false
+		}
+
+		b.setState(BreakerHalfOpen)
+		b.probes, b.successes = 0, 0
+		fallthrough
+	case BreakerHalfOpen:
+		if b.probes >= b.policy.HalfOpenRequests {
+			return false
+		}
+
+		b.probes++
+		return true
+	default:
+		if now.Sub(b.windowStart) > b.policy.Window {
+			b.windowStart, b.requests, b.failures = now, 0, 0
+		}
+
+		return true
+	}
+}
+
+func (b *breaker) record(now time.Time, failure bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case BreakerHalfOpen:
+		if failure {
+			b.open(now)
+			return
+		}
+
+		if b.successes++; b.successes >= b.policy.HalfOpenRequests {
+			b.setState(BreakerClosed)
+			b.windowStart, b.requests, b.failures = now, 0, 0
+		}
+	case BreakerClosed:
+		b.requests++
+		if failure {
+			b.failures++
+		}
+
+		if b.requests >= b.policy.MinRequests && float64(b.failures) >= b.policy.FailureRatio*float64(b.requests) {
+			b.open(now)
+		}
+	case BreakerOpen:
+		// the request was started before opening
+	}
+}
+
+// release frees the half-open slot of a request that didn't test the downstream.
+func (b *breaker) release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == BreakerHalfOpen && b.probes > 0 {
+		b.probes--
+	}
+}
+
+func (b *breaker) open(now time.Time) {
+	b.setState(BreakerOpen)
+	b.openedAt = now
+}
+
+func (b *breaker) setState(state BreakerState) {
+	b.state = state
+	b.gauge.Set(float64(state))
+}
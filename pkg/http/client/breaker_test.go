@@ -0,0 +1,113 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	pph "github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	var (
+		srv, attempts = newFlakyServer(t, 2, nil)
+		registry      = prometheus.NewRegistry()
+		// HalfOpenRequests is taken from defaults
+		policy = BreakerPolicy{
+			Window:       time.Minute,
+			MinRequests:  2,
+			FailureRatio: 0.5,
+			CoolDown:     time.Millisecond * 50,
+		}
+	)
+	c, err := NewClient("test", WithPrometheusRegisterer(registry), WithCircuitBreaker(policy))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	get := func() error {
+		resp, err := c.Get(srv.URL)
+		if err == nil {
+			_ = resp.Body.Close()
+		}
+
+		return err
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := get(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := get(); !errors.Is(err, ErrCircuitOpen) || attempts.Load() != 2 {
+		t.Errorf("get() = %v after %d attempts, want %v after 2", err, attempts.Load(), ErrCircuitOpen)
+	}
+
+	if got := gaugeValue(t, registry, "test_http_outgoing_circuit_breaker_state"); got != float64(BreakerOpen) {
+		t.Errorf("circuit_breaker_state = %v, want %v", got, BreakerOpen)
+	}
+
+	time.Sleep(policy.CoolDown)
+	if err := get(); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := gaugeValue(t, registry, "test_http_outgoing_circuit_breaker_state"); got != float64(BreakerClosed) {
+		t.Errorf("circuit_breaker_state = %v, want %v", got, BreakerClosed)
+	}
+}
+
+func TestCircuitBreakerCanceledProbe(t *testing.T) {
+	var (
+		gauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "state"}, []string{"target"})
+		errs  = []error{errors.New("down"), errors.New("down"), context.Canceled, nil}
+	)
+	rt := InstrumentRoundTripperBreaker(BreakerPolicy{MinRequests: 2, CoolDown: time.Millisecond}, "test", gauge,
+		pph.RoundTripperFunc(func(r *http.Request) (*http.Response, error) {
+			err := errs[0]
+			errs = errs[1:]
+			if err != nil {
+				return nil, err
+			}
+			return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil
+		}))
+
+	req := httptest.NewRequest(http.MethodGet, "http://example.com", http.NoBody)
+	state := func() float64 { return testutil.ToFloat64(gauge.WithLabelValues("example.com")) }
+	for i := 0; i < 2; i++ {
+		_, _ = rt.RoundTrip(req)
+	}
+	time.Sleep(time.Millisecond * 5)
+
+	// the canceled probe is neutral and frees its slot for the next one
+	if _, err := rt.RoundTrip(req); !errors.Is(err, context.Canceled) || state() != float64(BreakerHalfOpen) {
+		t.Fatalf("canceled probe: err = %v, state = %v, want half-open", err, state())
+	}
+	if _, err := rt.RoundTrip(req); err != nil || state() != float64(BreakerClosed) {
+		t.Errorf("probe: err = %v, state = %v, want closed", err, state())
+	}
+}
+
+func gaugeValue(t *testing.T, registry *prometheus.Registry, name string) float64 {
+	t.Helper()
+
+	families, err := registry.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, family := range families {
+		if family.GetName() == name {
+			return family.GetMetric()[0].GetGauge().GetValue()
+		}
+	}
+
+	t.Fatalf("%s isn't registered", name)
+	return 0
+}
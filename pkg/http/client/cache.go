@@ -0,0 +1,400 @@
+package client
+
+import (
+	"bytes"
+	"container/list"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	pph "github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// IdentityHeaders tell callers apart for the cache and coalescing.
+var IdentityHeaders = []string{"X-Tenant-Id", "X-User-Id"}
+
+const (
+	maxCacheDrain = 64 << 10
+
+	cacheHit         = "hit"
+	cacheMiss        = "miss"
+	cacheRevalidated = "revalidated"
+)
+
+type (
+	// CacheStore must be safe for concurrent use.
+	CacheStore interface {
+		Get(key string) (*CachedResponse, bool)
+		Set(key string, entry *CachedResponse)
+		Delete(key string)
+		DeletePrefix(prefix string)
+	}
+
+	CachedResponse struct {
+		StatusCode int
+		Header     http.Header
+		Body       []byte
+		Vary       http.Header
+		Stored     time.Time
+	}
+
+	LRUStore struct {
+		size int
+
+		mu      sync.Mutex
+		order   *list.List
+		entries map[string]*list.Element
+	}
+
+	lruEntry struct {
+		key   string
+		entry *CachedResponse
+	}
+
+	cacheControl map[string]string
+
+	cachingBody struct {
+		io.ReadCloser
+		buf   bytes.Buffer
+		store func([]byte)
+	}
+)
+
+func NewLRUStore(size int) *LRUStore {
+	return &LRUStore{size: size, order: list.New(), entries: make(map[string]*list.Element, size)}
+}
+
+func (s *LRUStore) Get(key string) (*CachedResponse, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	elem, ok := s.entries[key]
+	if !ok {
+		return nil, false
+	}
+	s.order.MoveToFront(elem)
+
+	return elem.Value.(*lruEntry).entry, true
+}
+
+func (s *LRUStore) Set(key string, entry *CachedResponse) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if elem, ok := s.entries[key]; ok {
+		elem.Value.(*lruEntry).entry = entry
+		s.order.MoveToFront(elem)
+		return
+	}
+
+	s.entries[key] = s.order.PushFront(&lruEntry{key: key, entry: entry})
+	for s.order.Len() > s.size {
+		oldest := s.order.Back()
+		s.order.Remove(oldest)
+		delete(s.entries, oldest.Value.(*lruEntry).key)
+	}
+}
+
+func (s *LRUStore) Delete(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if elem, ok := s.entries[key]; ok {
+		s.order.Remove(elem)
+		delete(s.entries, key)
+	}
+}
+
+func (s *LRUStore) DeletePrefix(prefix string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for key, elem := range s.entries {
+		if strings.HasPrefix(key, prefix) {
+			s.order.Remove(elem)
+			delete(s.entries, key)
+		}
+	}
+}
+
+// InstrumentRoundTripperCache serves GET requests from the store by RFC 9111, stale responses
+// are revalidated. The store is shared by callers, so requests with Authorization aren't cached.
+func InstrumentRoundTripperCache(store CacheStore, headers []string, counter *prometheus.CounterVec, next http.RoundTripper) pph.RoundTripperFunc {
+	return func(r *http.Request) (*http.Response, error) {
+		key := cacheKey(r, headers)
+		if r.Method != http.MethodGet {
+			resp, err := next.RoundTrip(r)
+			if err == nil && !isSafeMethod(r.Method) && resp.StatusCode < http.StatusBadRequest {
+				store.DeletePrefix(urlKey(r))
+			}
+
+			return resp, err
+		}
+
+		reqControl := parseCacheControl(r.Header)
+		if _, ok := reqControl["no-store"]; ok || r.Header.Get("Authorization") != "" || r.Header.Get("Range") != "" ||
+			r.Header.Get("If-None-Match") != "" || r.Header.Get("If-Modified-Since") != "" {
+			return next.RoundTrip(r)
+		}
+
+		entry, ok := store.Get(key)
+		if ok && !entry.matches(r) {
+			entry, ok = nil, false
+		}
+
+		if ok && entry.fresh(reqControl) {
+			counter.WithLabelValues(cacheHit).Inc()
+			return entry.response(r), nil
+		}
+
+		validated := ok && (entry.Header.Get("ETag") != "" || entry.Header.Get("Last-Modified") != "")
+		if validated {
+			r = r.Clone(r.Context())
+			if etag := entry.Header.Get("ETag"); etag != "" {
+				r.Header.Set("If-None-Match", etag)
+			}
+			if modified := entry.Header.Get("Last-Modified"); modified != "" {
+				r.Header.Set("If-Modified-Since", modified)
+			}
+		}
+
+		resp, err := next.RoundTrip(r)
+		if err != nil {
+			return nil, err
+		}
+
+		if validated && resp.StatusCode == http.StatusNotModified {
+			_ = resp.Body.Close()
+			counter.WithLabelValues(cacheRevalidated).Inc()
+
+			updated := *entry
+			updated.Header = entry.Header.Clone()
+			for name, values := range resp.Header {
+				updated.Header[name] = values
+			}
+			updated.Stored = time.Now()
+			store.Set(key, &updated)
+
+			return updated.response(r), nil
+		}
+
+		counter.WithLabelValues(cacheMiss).Inc()
+		if !storable(resp) {
+			store.Delete(key)
+			return resp, nil
+		}
+
+		stored := &CachedResponse{StatusCode: resp.StatusCode, Header: resp.Header.Clone(), Vary: make(http.Header)}
+		for _, name := range varyHeaders(resp.Header) {
+			stored.Vary[name] = r.Header.Values(name)
+		}
+		resp.Body = &cachingBody{ReadCloser: resp.Body, store: func(body []byte) {
+			stored.Body, stored.Stored = body, time.Now()
+			store.Set(key, stored)
+		}}
+
+		return resp, nil
+	}
+}
+
+func cacheKey(r *http.Request, headers []string) string {
+	var key strings.Builder
+	key.WriteString(urlKey(r))
+	for i, name := range headers {
+		if i > 0 {
+			key.WriteByte('\n')
+		}
+		key.WriteString(strings.Join(r.Header.Values(name), ","))
+	}
+
+	return key.String()
+}
+
+// urlKey prefixes the keys of every identity for the URL.
+func urlKey(r *http.Request) string {
+	return r.URL.String() + "\n"
+}
+
+func (b *cachingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if b.store == nil {
+		return n, err
+	}
+
+	if b.buf.Len()+n > defaultMaxResponseSize {
+		b.store, b.buf = nil, bytes.Buffer{}
+		return n, err
+	}
+	b.buf.Write(p[:n])
+
+	if err == io.EOF {
+		b.store(b.buf.Bytes())
+		b.store = nil
+	}
+
+	return n, err
+}
+
+// Close stores the body if its rest is small, decoders may stop before io.EOF.
+func (b *cachingBody) Close() error {
+	if b.store != nil {
+		_, _ = io.Copy(io.Discard, io.LimitReader(b, maxCacheDrain))
+	}
+
+	return b.ReadCloser.Close()
+}
+
+func (e *CachedResponse) matches(r *http.Request) bool {
+	for name, values := range e.Vary {
+		if strings.Join(values, ",") != strings.Join(r.Header.Values(name), ",") {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (e *CachedResponse) fresh(reqControl cacheControl) bool {
+	if _, ok := reqControl["no-cache"]; ok {
+		return false
+	}
+
+	control := parseCacheControl(e.Header)
+	if _, ok := control["no-cache"]; ok {
+		return false
+	}
+
+	age := e.age()
+	if maxAge, ok := reqControl.seconds("max-age"); ok && age > maxAge {
+		return false
+	}
+
+	return age < e.lifetime(control)
+}
+
+func (e *CachedResponse) lifetime(control cacheControl) time.Duration {
+	if maxAge, ok := control.seconds("max-age"); ok {
+		return maxAge
+	}
+
+	expires, err := http.ParseTime(e.Header.Get("Expires"))
+	if err != nil {
+		return 0
+	}
+
+	date, err := http.ParseTime(e.Header.Get("Date"))
+	if err != nil {
+		date = e.Stored
+	}
+
+	return expires.Sub(date)
+}
+
+func (e *CachedResponse) age() time.Duration {
+	age := time.Since(e.Stored)
+	if seconds, err := strconv.Atoi(e.Header.Get("Age")); err == nil && seconds > 0 {
+		age += time.Duration(seconds) * time.Second
+	}
+
+	return age
+}
+
+func (e *CachedResponse) response(r *http.Request) *http.Response {
+	header := e.Header.Clone()
+	header.Set("Age", strconv.Itoa(int(e.age().Seconds())))
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", e.StatusCode, http.StatusText(e.StatusCode)),
+		StatusCode:    e.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(e.Body)),
+		ContentLength: int64(len(e.Body)),
+		Request:       r,
+	}
+}
+
+func storable(resp *http.Response) bool {
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusNonAuthoritativeInfo, http.StatusNoContent, http.StatusMultipleChoices,
+		http.StatusMovedPermanently, http.StatusNotFound, http.StatusGone:
+	default:
+		return false
+	}
+
+	control := parseCacheControl(resp.Header)
+	if _, ok := control["no-store"]; ok {
+		return false
+	}
+	if _, ok := control["private"]; ok {
+		return false
+	}
+
+	for _, name := range varyHeaders(resp.Header) {
+		if name == "*" {
+			return false
+		}
+	}
+
+	if _, ok := control["max-age"]; ok {
+		return true
+	}
+
+	return resp.Header.Get("Expires") != "" || resp.Header.Get("ETag") != "" || resp.Header.Get("Last-Modified") != ""
+}
+
+func varyHeaders(header http.Header) (names []string) {
+	for _, value := range header.Values("Vary") {
+		for _, name := range strings.Split(value, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				names = append(names, http.CanonicalHeaderKey(name))
+			}
+		}
+	}
+
+	return names
+}
+
+func parseCacheControl(header http.Header) cacheControl {
+	control := cacheControl{}
+	for _, value := range header.Values("Cache-Control") {
+		for _, directive := range strings.Split(value, ",") {
+			name, arg, _ := strings.Cut(strings.TrimSpace(directive), "=")
+			if name != "" {
+				control[strings.ToLower(name)] = strings.Trim(arg, `"`)
+			}
+		}
+	}
+
+	return control
+}
+
+func (c cacheControl) seconds(name string) (time.Duration, bool) {
+	arg, ok := c[name]
+	if !ok {
+		return 0, false
+	}
+
+	seconds, err := strconv.ParseInt(arg, 10, 64)
+	if err != nil || seconds < 0 {
+		return 0, true
+	}
+
+	return time.Duration(seconds) * time.Second, true
+}
+
+func isSafeMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,202 @@
+package client
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestCache(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		requests = map[string]int{}
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests[r.Method+" "+r.URL.Path]++
+		mu.Unlock()
+
+		switch r.URL.Path {
+		case "/fresh":
+			w.Header().Set("Cache-Control", "max-age=60")
+		case "/etag":
+			w.Header().Set("Cache-Control", "no-cache")
+			w.Header().Set("ETag", `"v1"`)
+			if r.Header.Get("If-None-Match") == `"v1"` {
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+		case "/no-store":
+			w.Header().Set("Cache-Control", "no-store")
+		case "/private":
+			w.Header().Set("Cache-Control", "private, max-age=60")
+		}
+		_, _ = w.Write([]byte("body"))
+	}))
+	defer srv.Close()
+
+	registry := prometheus.NewRegistry()
+	c, err := NewClient("test", WithPrometheusRegisterer(registry), WithCache(NewLRUStore(10)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	do := func(method, path string) {
+		t.Helper()
+
+		req, _ := http.NewRequest(method, srv.URL+path, http.NoBody)
+		resp, err := c.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+
+		if body, _ := io.ReadAll(resp.Body); method == http.MethodGet && string(body) != "body" {
+			t.Errorf("%s %s: body = %q, want %q", method, path, body, "body")
+		}
+	}
+
+	for _, path := range []string{"/fresh", "/etag", "/no-store", "/private"} {
+		do(http.MethodGet, path)
+		do(http.MethodGet, path)
+	}
+	do(http.MethodPost, "/fresh")
+	do(http.MethodGet, "/fresh")
+
+	want := map[string]int{"GET /fresh": 2, "POST /fresh": 1, "GET /etag": 2, "GET /no-store": 2, "GET /private": 2}
+	for key, n := range want {
+		if requests[key] != n {
+			t.Errorf("%s is requested %d times, want %d", key, requests[key], n)
+		}
+	}
+
+	metrics := `
+# HELP test_http_outgoing_cache_requests_total A counter for cacheable requests by result: hit, miss or revalidated.
+# TYPE test_http_outgoing_cache_requests_total counter
+test_http_outgoing_cache_requests_total{result="hit"} 1
+test_http_outgoing_cache_requests_total{result="miss"} 7
+test_http_outgoing_cache_requests_total{result="revalidated"} 1
+`
+	if err := testutil.GatherAndCompare(registry, strings.NewReader(metrics), "test_http_outgoing_cache_requests_total"); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestLRUStore(t *testing.T) {
+	store := NewLRUStore(2)
+	store.Set("a", &CachedResponse{})
+	store.Set("b", &CachedResponse{})
+	store.Get("a")
+	store.Set("c", &CachedResponse{})
+
+	for key, want := range map[string]bool{"a": true, "b": false, "c": true} {
+		if _, ok := store.Get(key); ok != want {
+			t.Errorf("Get(%s) = %v, want %v", key, ok, want)
+		}
+	}
+}
+
+func TestCacheIdentity(t *testing.T) {
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.Header().Set("Cache-Control", "max-age=60")
+		_, _ = w.Write([]byte(r.Header.Get("X-Tenant-Id") + r.Header.Get("Authorization")))
+	}))
+	defer srv.Close()
+
+	c, err := NewClient("test", WithPrometheusRegisterer(prometheus.NewRegistry()), WithCache(NewLRUStore(10)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	get := func(header, value string) string {
+		t.Helper()
+
+		req, _ := http.NewRequest(http.MethodGet, srv.URL, http.NoBody)
+		req.Header.Set(header, value)
+		resp, err := c.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+
+		body, _ := io.ReadAll(resp.Body)
+		return string(body)
+	}
+
+	for _, tenant := range []string{"a", "b", "a", "b"} {
+		if got := get("X-Tenant-Id", tenant); got != tenant {
+			t.Errorf("tenant %s got %q", tenant, got)
+		}
+	}
+	if requests.Load() != 2 {
+		t.Errorf("%d requests for 2 tenants, want 2", requests.Load())
+	}
+
+	req, _ := http.NewRequest(http.MethodPost, srv.URL, http.NoBody)
+	req.Header.Set("X-Tenant-Id", "a")
+	resp, err := c.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+	for _, tenant := range []string{"a", "b"} {
+		get("X-Tenant-Id", tenant)
+	}
+	if requests.Load() != 5 {
+		t.Errorf("%d requests, want the URL invalidated for both tenants", requests.Load())
+	}
+
+	for _, token := range []string{"Bearer a", "Bearer b"} {
+		if got := get("Authorization", token); got != token {
+			t.Errorf("token %s got %q", token, got)
+		}
+	}
+	if requests.Load() != 7 {
+		t.Errorf("%d requests, want requests with Authorization not cached", requests.Load())
+	}
+}
+
+func TestCacheStoreOnClose(t *testing.T) {
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.Header().Set("Cache-Control", "max-age=60")
+		if r.URL.Path == "/large" {
+			_, _ = w.Write(make([]byte, maxCacheDrain*4))
+			return
+		}
+		_, _ = w.Write([]byte("body"))
+	}))
+	defer srv.Close()
+
+	c, err := NewClient("test", WithPrometheusRegisterer(prometheus.NewRegistry()), WithCache(NewLRUStore(10)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for path, want := range map[string]int32{"/small": 1, "/large": 2} {
+		requests.Store(0)
+		for i := 0; i < 2; i++ {
+			resp, err := c.Get(srv.URL + path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := io.ReadFull(resp.Body, make([]byte, 2)); err != nil {
+				t.Fatal(err)
+			}
+			_ = resp.Body.Close()
+		}
+		if requests.Load() != want {
+			t.Errorf("%s is requested %d times, want %d", path, requests.Load(), want)
+		}
+	}
+}
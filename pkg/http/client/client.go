@@ -7,6 +7,7 @@ import (
 
 	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
 	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
 
 	"github.com/prometheus/client_golang/prometheus"
 	pph "github.com/prometheus/client_golang/prometheus/promhttp"
@@ -15,15 +16,41 @@ import (
 )
 
 type Config struct {
-	Timeout            time.Duration
-	MaxIdleConnections int
-	IdleConnTimeout    time.Duration
-	DisableCompression bool
+	Timeout                   time.Duration
+	MaxIdleConnections        int
+	MaxIdleConnectionsPerHost int
+	IdleConnTimeout           time.Duration
+	DisableCompression        bool
+	TransportConfig
+	Transport http.RoundTripper
 
 	PrometheusRegisterer prometheus.Registerer
 	ConstLabels          map[string]string
 	ServiceName          string
+	ServiceLabel         bool
 	OTELTraceProvider    trace.TracerProvider
+
+	Retry         *RetryPolicy
+	Breaker       *BreakerPolicy
+	Limits        map[string]Limit
+	LimitFailFast bool
+
+	OAuth2       *OAuth2Config
+	Signer       RequestSigner
+	Cache        CacheStore
+	CacheHeaders []string
+
+	PropagateHeaders []string
+
+	Hedge           *HedgePolicy
+	Coalesce        bool
+	CoalesceHeaders []string
+
+	Faults *FaultInjector
+
+	RouteTemplates []string
+	OpenAPI        *OpenAPISpec
+	Logger         *zap.Logger
 }
 
 func defaultConfig() Config {
@@ -35,13 +62,16 @@ func defaultConfig() Config {
 
 	traceProvider, _ := tracing.New(context.Background(), tracing.Config{})
 	return Config{
-		Timeout:              defaultTimeout,
-		MaxIdleConnections:   defaultMaxIdleConnections,
-		IdleConnTimeout:      defaultIdleConnTimeout,
-		DisableCompression:   false,
-		PrometheusRegisterer: prometheus.DefaultRegisterer,
-		ConstLabels:          map[string]string{},
-		OTELTraceProvider:    traceProvider,
+		Timeout:                   defaultTimeout,
+		MaxIdleConnections:        defaultMaxIdleConnections,
+		MaxIdleConnectionsPerHost: defaultMaxIdleConnections,
+		IdleConnTimeout:           defaultIdleConnTimeout,
+		DisableCompression:        false,
+		TransportConfig:           defaultTransportConfig(),
+		PrometheusRegisterer:      prometheus.DefaultRegisterer,
+		ConstLabels:               map[string]string{},
+		OTELTraceProvider:         traceProvider,
+		Logger:                    zap.NewNop(),
 	}
 }
 
@@ -52,16 +82,22 @@ func NewClient(name string, opts ...OptionFunc) (_ *http.Client, err error) {
 	}
 
 	httpClient := &http.Client{
-		Transport: &http.Transport{
-			MaxIdleConns:       config.MaxIdleConnections,
-			IdleConnTimeout:    config.IdleConnTimeout,
-			DisableCompression: config.DisableCompression,
-		},
-		Timeout: config.Timeout,
+		Transport: config.Transport,
+		Timeout:   config.Timeout,
+	}
+	if httpClient.Transport == nil {
+		if httpClient.Transport, err = newTransport(config); err != nil {
+			return nil, err
+		}
 	}
 
-	if config.ServiceName != "" {
-		config.ConstLabels["service"] = config.ServiceName
+	if config.ServiceName != "" || config.ServiceLabel {
+		labels := make(map[string]string, len(config.ConstLabels)+1)
+		for key, val := range config.ConstLabels {
+			labels[key] = val
+		}
+		labels["service"] = config.ServiceName
+		config.ConstLabels = labels
 	}
 
 	if httpClient, err = instrumentClientWithConstLabels(name, httpClient, config); err != nil {
@@ -71,7 +107,11 @@ func NewClient(name string, opts ...OptionFunc) (_ *http.Client, err error) {
 	httpClient.Transport = otelhttp.NewTransport(
 		httpClient.Transport,
 		otelhttp.WithTracerProvider(config.OTELTraceProvider),
+		otelhttp.WithSpanNameFormatter(spanName),
 	)
+	if len(config.RouteTemplates) > 0 {
+		httpClient.Transport = InstrumentRoundTripperOperation(config.RouteTemplates, httpClient.Transport)
+	}
 
 	return httpClient, nil
 }
@@ -88,7 +128,7 @@ func instrumentClientWithConstLabels(name string, c *http.Client, config Config)
 				Help:        "A counter for outgoing requests from the wrapped client.",
 				ConstLabels: config.ConstLabels,
 			},
-			[]string{"code", "method"},
+			[]string{"code", "method", labelOperation},
 		),
 		errRequests: prometheus.NewCounterVec(
 			prometheus.CounterOpts{
@@ -98,7 +138,7 @@ func instrumentClientWithConstLabels(name string, c *http.Client, config Config)
 				Help:        "A counter for outgoing requests with errors.",
 				ConstLabels: config.ConstLabels,
 			},
-			[]string{},
+			[]string{labelOperation},
 		),
 		duration: prometheus.NewHistogramVec(
 			prometheus.HistogramOpts{
@@ -109,7 +149,7 @@ func instrumentClientWithConstLabels(name string, c *http.Client, config Config)
 				Buckets:     prometheus.DefBuckets,
 				ConstLabels: config.ConstLabels,
 			},
-			[]string{"method"},
+			[]string{"method", labelOperation},
 		),
 		dnsDuration: prometheus.NewHistogramVec(
 			prometheus.HistogramOpts{
@@ -133,13 +173,107 @@ func instrumentClientWithConstLabels(name string, c *http.Client, config Config)
 			},
 			[]string{"event"},
 		),
-		inflight: prometheus.NewGauge(prometheus.GaugeOpts{
-			Namespace:   name,
-			Subsystem:   subsystemHTTPOutgoing,
-			Name:        "in_flight_requests",
-			Help:        "A gauge of in-flight outgoing requests for the wrapped client.",
-			ConstLabels: config.ConstLabels,
-		}),
+		retries: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace:   name,
+				Subsystem:   subsystemHTTPOutgoing,
+				Name:        "retries_total",
+				Help:        "A counter for retried outgoing requests.",
+				ConstLabels: config.ConstLabels,
+			},
+			[]string{"method"},
+		),
+		breakerState: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace:   name,
+				Subsystem:   subsystemHTTPOutgoing,
+				Name:        "circuit_breaker_state",
+				Help:        "A state of circuit breaker: 0 - closed, 1 - open, 2 - half-open.",
+				ConstLabels: config.ConstLabels,
+			},
+			[]string{"target"},
+		),
+		limitWait: prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Namespace:   name,
+				Subsystem:   subsystemHTTPOutgoing,
+				Name:        "limit_wait_duration_histogram_seconds",
+				Help:        "A histogram of waits for rate and concurrency limits.",
+				Buckets:     prometheus.DefBuckets,
+				ConstLabels: config.ConstLabels,
+			},
+			[]string{"host", "kind"},
+		),
+		limitRejections: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace:   name,
+				Subsystem:   subsystemHTTPOutgoing,
+				Name:        "limit_rejections_total",
+				Help:        "A counter for requests rejected by rate and concurrency limits.",
+				ConstLabels: config.ConstLabels,
+			},
+			[]string{"host", "kind"},
+		),
+		violations: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace:   name,
+				Subsystem:   subsystemHTTPOutgoing,
+				Name:        "contract_violations_total",
+				Help:        "A counter for OpenAPI contract violations by requests and responses.",
+				ConstLabels: config.ConstLabels,
+			},
+			[]string{labelOperation, "direction"},
+		),
+		connections: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace:   name,
+				Subsystem:   subsystemHTTPOutgoing,
+				Name:        "connections_total",
+				Help:        "A counter for connections got by outgoing requests, labeled by reuse of idle ones.",
+				ConstLabels: config.ConstLabels,
+			},
+			[]string{"reused"},
+		),
+		cache: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace:   name,
+				Subsystem:   subsystemHTTPOutgoing,
+				Name:        "cache_requests_total",
+				Help:        "A counter for cacheable requests by result: hit, miss or revalidated.",
+				ConstLabels: config.ConstLabels,
+			},
+			[]string{"result"},
+		),
+		coalesced: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace:   name,
+				Subsystem:   subsystemHTTPOutgoing,
+				Name:        "coalesced_requests_total",
+				Help:        "A counter for requests deduplicated by identical in-flight ones.",
+				ConstLabels: config.ConstLabels,
+			},
+			[]string{"method"},
+		),
+		hedged: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace:   name,
+				Subsystem:   subsystemHTTPOutgoing,
+				Name:        "hedged_requests_total",
+				Help:        "A counter for hedged attempts sent after the delay.",
+				ConstLabels: config.ConstLabels,
+			},
+			[]string{"method"},
+		),
+		inflight: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace:   name,
+				Subsystem:   subsystemHTTPOutgoing,
+				Name:        "in_flight_requests",
+				Help:        "A gauge of in-flight outgoing requests for the wrapped client.",
+				ConstLabels: config.ConstLabels,
+			},
+			[]string{labelOperation},
+		),
 	}
 
 	trace := &pph.InstrumentTrace{
@@ -149,31 +283,93 @@ func instrumentClientWithConstLabels(name string, c *http.Client, config Config)
 		TLSHandshakeDone:  func(t float64) { collector.tlsDuration.WithLabelValues("tls_handshake_done").Observe(t) },
 	}
 
+	// limits are waited out of the duration, so it's a network latency
+	operation := pph.WithLabelFromCtx(labelOperation, OperationFromContext)
+
+	transport := c.Transport
+	if config.Faults != nil {
+		transport = InstrumentRoundTripperFaults(config.Faults, transport)
+	}
+
+	transport = pph.InstrumentRoundTripperDuration(collector.duration, transport, operation)
+	if len(config.Limits) > 0 {
+		metrics := limitMetrics{wait: collector.limitWait, rejections: collector.limitRejections}
+		transport = InstrumentRoundTripperLimit(config.Limits, config.LimitFailFast, metrics, transport)
+	}
+
+	transport = InstrumentRoundTripperInFlight(
+		collector.inflight, InstrumentRoundTripperErrorCounter(
+			collector.errRequests, pph.InstrumentRoundTripperCounter(
+				collector.requests, pph.InstrumentRoundTripperTrace(
+					trace, InstrumentRoundTripperConnections(collector.connections, transport),
+				), operation,
+			),
+		),
+	)
+	if config.Signer != nil {
+		transport = InstrumentRoundTripperSigner(config.Signer, transport)
+	}
+	if config.OAuth2 != nil {
+		oauth2 := *config.OAuth2
+		if oauth2.Transport == nil {
+			// token requests aren't signed and don't get propagated headers
+			oauth2.Transport = c.Transport
+		}
+		transport = InstrumentRoundTripperOAuth2(oauth2, transport)
+	}
+	if config.Hedge != nil {
+		transport = InstrumentRoundTripperHedge(*config.Hedge, collector.hedged, transport)
+	}
+	if config.Breaker != nil {
+		transport = InstrumentRoundTripperBreaker(*config.Breaker, config.ServiceName, collector.breakerState, transport)
+	}
+	if config.Retry != nil {
+		transport = InstrumentRoundTripperRetry(*config.Retry, collector.retries, transport)
+	}
+	if config.OpenAPI != nil {
+		validated, err := InstrumentRoundTripperOpenAPI(*config.OpenAPI, collector.violations, config.Logger, transport)
+		if err != nil {
+			return nil, err
+		}
+		transport = validated
+	}
+	if config.Coalesce {
+		transport = InstrumentRoundTripperCoalesce(config.CoalesceHeaders, collector.coalesced, transport)
+	}
+	if config.Cache != nil {
+		transport = InstrumentRoundTripperCache(config.Cache, config.CacheHeaders, collector.cache, transport)
+	}
+	// cache and coalescing see propagated identity headers, so callers don't share responses
+	if len(config.PropagateHeaders) > 0 {
+		transport = InstrumentRoundTripperPropagation(config.PropagateHeaders, transport)
+	}
+
 	resultClient := &http.Client{
 		CheckRedirect: c.CheckRedirect,
 		Jar:           c.Jar,
 		Timeout:       c.Timeout,
-		Transport: pph.InstrumentRoundTripperInFlight(
-			collector.inflight, InstrumentRoundTripperErrorCounter(
-				collector.errRequests, pph.InstrumentRoundTripperCounter(
-					collector.requests, pph.InstrumentRoundTripperTrace(
-						trace, pph.InstrumentRoundTripperDuration(collector.duration, c.Transport),
-					),
-				),
-			),
-		),
+		Transport:     transport,
 	}
 
 	return resultClient, config.PrometheusRegisterer.Register(collector)
 }
 
 type outgoingInstrumentation struct {
-	duration    *prometheus.HistogramVec
-	requests    *prometheus.CounterVec
-	errRequests *prometheus.CounterVec
-	dnsDuration *prometheus.HistogramVec
-	tlsDuration *prometheus.HistogramVec
-	inflight    prometheus.Gauge
+	duration        *prometheus.HistogramVec
+	requests        *prometheus.CounterVec
+	errRequests     *prometheus.CounterVec
+	dnsDuration     *prometheus.HistogramVec
+	tlsDuration     *prometheus.HistogramVec
+	retries         *prometheus.CounterVec
+	breakerState    *prometheus.GaugeVec
+	limitWait       *prometheus.HistogramVec
+	limitRejections *prometheus.CounterVec
+	violations      *prometheus.CounterVec
+	connections     *prometheus.CounterVec
+	cache           *prometheus.CounterVec
+	coalesced       *prometheus.CounterVec
+	hedged          *prometheus.CounterVec
+	inflight        *prometheus.GaugeVec
 }
 
 var _ prometheus.Collector = &outgoingInstrumentation{}
@@ -184,6 +380,15 @@ func (i *outgoingInstrumentation) Describe(in chan<- *prometheus.Desc) {
 	i.errRequests.Describe(in)
 	i.dnsDuration.Describe(in)
 	i.tlsDuration.Describe(in)
+	i.retries.Describe(in)
+	i.breakerState.Describe(in)
+	i.limitWait.Describe(in)
+	i.limitRejections.Describe(in)
+	i.violations.Describe(in)
+	i.connections.Describe(in)
+	i.cache.Describe(in)
+	i.coalesced.Describe(in)
+	i.hedged.Describe(in)
 	i.inflight.Describe(in)
 }
 
@@ -193,6 +398,15 @@ func (i *outgoingInstrumentation) Collect(in chan<- prometheus.Metric) {
 	i.errRequests.Collect(in)
 	i.dnsDuration.Collect(in)
 	i.tlsDuration.Collect(in)
+	i.retries.Collect(in)
+	i.breakerState.Collect(in)
+	i.limitWait.Collect(in)
+	i.limitRejections.Collect(in)
+	i.violations.Collect(in)
+	i.connections.Collect(in)
+	i.cache.Collect(in)
+	i.coalesced.Collect(in)
+	i.hedged.Collect(in)
 	i.inflight.Collect(in)
 }
 
@@ -200,7 +414,7 @@ func InstrumentRoundTripperErrorCounter(counter *prometheus.CounterVec, next htt
 	return func(r *http.Request) (*http.Response, error) {
 		resp, err := next.RoundTrip(r)
 		if err != nil {
-			counter.WithLabelValues().Inc()
+			counter.WithLabelValues(OperationFromContext(r.Context())).Inc()
 		}
 		return resp, err
 	}
@@ -0,0 +1,248 @@
+package clienttest
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/fusionmedialimited/backend-infra-libraries/v3/pkg/primitives"
+)
+
+const (
+	ModeReplay Mode = iota
+	ModeRecord
+
+	EnvRecord = "CLIENTTEST_RECORD"
+
+	redactedValue = "REDACTED"
+)
+
+var ErrNoInteraction = errors.New("no recorded interaction")
+
+type (
+	Mode int
+
+	// Cassette records interactions into JSON file or replays them by method, URL and body.
+	Cassette struct {
+		RedactHeaders []string
+
+		path string
+		mode Mode
+		next http.RoundTripper
+
+		mu           sync.Mutex
+		interactions []Interaction
+		used         []bool
+	}
+
+	Interaction struct {
+		Request  Request  `json:"request"`
+		Response Response `json:"response"`
+	}
+
+	Request struct {
+		Method string      `json:"method"`
+		URL    string      `json:"url"`
+		Header http.Header `json:"header,omitempty"`
+		Body   string      `json:"body,omitempty"`
+	}
+
+	Response struct {
+		Status int         `json:"status"`
+		Header http.Header `json:"header,omitempty"`
+		Body   string      `json:"body,omitempty"`
+	}
+)
+
+func NewCassette(path string, mode Mode, next http.RoundTripper) (*Cassette, error) {
+	c := &Cassette{
+		RedactHeaders: []string{"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie", "X-Api-Key"},
+		path:          path,
+		mode:          mode,
+		next:          next,
+	}
+	if mode == ModeRecord {
+		return c, nil
+	}
+
+	data, err := os.ReadFile(path) //nolint:gosec // path is provided by test
+	if err != nil {
+		return nil, fmt.Errorf("read cassette: %w", err)
+	}
+
+	if err = primitives.UnmarshalJSON(data, &c.interactions); err != nil {
+		return nil, err
+	}
+	c.used = make([]bool, len(c.interactions))
+
+	return c, nil
+}
+
+// UseCassette replays the cassette or records it if EnvRecord is set.
+func UseCassette(t testing.TB, path string) *Cassette {
+	t.Helper()
+
+	mode := ModeReplay
+	if os.Getenv(EnvRecord) != "" {
+		mode = ModeRecord
+	}
+
+	c, err := NewCassette(path, mode, http.DefaultTransport)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		if err := c.Save(); err != nil {
+			t.Error(err)
+		}
+	})
+
+	return c
+}
+
+func (c *Cassette) RoundTrip(r *http.Request) (*http.Response, error) {
+	body, err := readBody(r)
+	if err != nil {
+		return nil, err
+	}
+
+	r = r.Clone(r.Context())
+	if r.Body != nil && r.Body != http.NoBody {
+		r.Body = io.NopCloser(strings.NewReader(body))
+	}
+
+	if c.mode == ModeRecord {
+		return c.record(r, body)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// prefer not used interactions, so repeated requests get recorded responses in order
+	found := -1
+	for i, interaction := range c.interactions {
+		if interaction.Request.Method == r.Method && interaction.Request.URL == r.URL.String() &&
+			interaction.Request.Body == body {
+			if found = i; !c.used[i] {
+				break
+			}
+		}
+	}
+
+	if found < 0 {
+		return nil, fmt.Errorf("%s %s: %w", r.Method, r.URL, ErrNoInteraction)
+	}
+	c.used[found] = true
+
+	return c.interactions[found].Response.toHTTP(r), nil
+}
+
+func (c *Cassette) Save() error {
+	if c.mode != ModeRecord {
+		return nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	data, err := primitives.MarshalJSON(c.interactions)
+	if err != nil {
+		return err
+	}
+
+	var indented bytes.Buffer
+	if err = json.Indent(&indented, data, "", "  "); err != nil {
+		return fmt.Errorf("indent cassette: %w", err)
+	}
+
+	if err = os.WriteFile(c.path, indented.Bytes(), 0o600); err != nil {
+		return fmt.Errorf("write cassette: %w", err)
+	}
+
+	return nil
+}
+
+func (c *Cassette) record(r *http.Request, body string) (*http.Response, error) {
+	resp, err := c.next.RoundTrip(r)
+	if err != nil {
+		return nil, err
+	}
+
+	respBody, err := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("read response: %w", err)
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.interactions = append(c.interactions, Interaction{
+		Request: Request{
+			Method: r.Method,
+			URL:    r.URL.String(),
+			Header: c.redact(r.Header),
+			Body:   body,
+		},
+		Response: Response{
+			Status: resp.StatusCode,
+			Header: c.redact(resp.Header),
+			Body:   string(respBody),
+		},
+	})
+
+	return resp, nil
+}
+
+func (c *Cassette) redact(header http.Header) http.Header {
+	header = header.Clone()
+	for _, key := range c.RedactHeaders {
+		if header.Get(key) != "" {
+			header.Set(key, redactedValue)
+		}
+	}
+
+	return header
+}
+
+func (r Response) toHTTP(req *http.Request) *http.Response {
+	header := r.Header.Clone()
+	if header == nil {
+		header = make(http.Header)
+	}
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", r.Status, http.StatusText(r.Status)),
+		StatusCode:    r.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader([]byte(r.Body))),
+		ContentLength: int64(len(r.Body)),
+		Request:       req,
+	}
+}
+
+func readBody(r *http.Request) (string, error) {
+	if r.Body == nil || r.Body == http.NoBody {
+		return "", nil
+	}
+
+	data, err := io.ReadAll(r.Body)
+	_ = r.Body.Close()
+	if err != nil {
+		return "", fmt.Errorf("read request: %w", err)
+	}
+
+	return string(data), nil
+}
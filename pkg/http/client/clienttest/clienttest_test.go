@@ -0,0 +1,110 @@
+package clienttest_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/fusionmedialimited/backend-infra-libraries/v3/pkg/http/client"
+	"github.com/fusionmedialimited/backend-infra-libraries/v3/pkg/http/client/clienttest"
+)
+
+func newClient(t *testing.T, transport http.RoundTripper) *http.Client {
+	t.Helper()
+
+	c, err := client.NewClient("test",
+		client.WithPrometheusRegisterer(prometheus.NewRegistry()),
+		client.WithTransport(transport),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return c
+}
+
+func post(t *testing.T, c *http.Client, url, body string) (string, error) {
+	t.Helper()
+
+	req, _ := http.NewRequestWithContext(context.Background(), http.MethodPost, url, strings.NewReader(body))
+	req.Header.Set("Authorization", "Bearer secret")
+	resp, err := c.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	return string(data), err
+}
+
+func TestCassette(t *testing.T) {
+	var (
+		path = filepath.Join(t.TempDir(), "cassette.json")
+		srv  = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			_, _ = w.Write(append([]byte("echo "), body...))
+		}))
+	)
+
+	recorder, err := clienttest.NewCassette(path, clienttest.ModeRecord, http.DefaultTransport)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got, err := post(t, newClient(t, recorder), srv.URL, "hello"); err != nil || got != "echo hello" {
+		t.Fatalf("record = %q, %v", got, err)
+	}
+
+	if err = recorder.Save(); err != nil {
+		t.Fatal(err)
+	}
+	srv.Close()
+
+	player, err := clienttest.NewCassette(path, clienttest.ModeReplay, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := newClient(t, player)
+	if got, err := post(t, c, srv.URL, "hello"); err != nil || got != "echo hello" {
+		t.Errorf("replay = %q, %v", got, err)
+	}
+
+	if _, err := post(t, c, srv.URL, "bye"); !errors.Is(err, clienttest.ErrNoInteraction) {
+		t.Errorf("replay of unknown request = %v, want %v", err, clienttest.ErrNoInteraction)
+	}
+}
+
+func TestStub(t *testing.T) {
+	stub := clienttest.NewStub().
+		JSON(http.MethodGet, "/users/1", http.StatusOK, map[string]int{"id": 1}).
+		Error("", "/down", io.ErrUnexpectedEOF)
+	c := newClient(t, stub)
+
+	resp, err := c.Get("http://users.svc/users/1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+
+	if string(body) != `{"id":1}` {
+		t.Errorf("body = %s", body)
+	}
+
+	if _, err = c.Get("http://users.svc/down"); !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("Get(/down) = %v, want %v", err, io.ErrUnexpectedEOF)
+	}
+
+	if len(stub.Requests()) != 2 {
+		t.Errorf("served %d requests, want 2", len(stub.Requests()))
+	}
+}
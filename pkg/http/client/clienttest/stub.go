@@ -0,0 +1,80 @@
+package clienttest
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/fusionmedialimited/backend-infra-libraries/v3/pkg/primitives"
+)
+
+var ErrNoStub = errors.New("no stub for request")
+
+type (
+	// Stub serves requests by the first handler matched by method and path.
+	Stub struct {
+		mu       sync.Mutex
+		handlers []stubHandler
+		requests []*http.Request
+	}
+
+	StubFunc func(r *http.Request) (*http.Response, error)
+
+	stubHandler struct {
+		method, path string
+		fn           StubFunc
+	}
+)
+
+func NewStub() *Stub {
+	return new(Stub)
+}
+
+func (s *Stub) Handle(method, path string, fn StubFunc) *Stub {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.handlers = append(s.handlers, stubHandler{method: method, path: path, fn: fn})
+	return s
+}
+
+func (s *Stub) JSON(method, path string, status int, body any) *Stub {
+	data, err := primitives.MarshalJSON(body)
+	return s.Handle(method, path, func(r *http.Request) (*http.Response, error) {
+		if err != nil {
+			return nil, err
+		}
+
+		resp := Response{Status: status, Header: http.Header{"Content-Type": {"application/json"}}, Body: string(data)}
+		return resp.toHTTP(r), nil
+	})
+}
+
+func (s *Stub) Error(method, path string, err error) *Stub {
+	return s.Handle(method, path, func(*http.Request) (*http.Response, error) {
+		return nil, err
+	})
+}
+
+func (s *Stub) Requests() []*http.Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]*http.Request(nil), s.requests...)
+}
+
+func (s *Stub) RoundTrip(r *http.Request) (*http.Response, error) {
+	s.mu.Lock()
+	s.requests = append(s.requests, r)
+	handlers := s.handlers
+	s.mu.Unlock()
+
+	for _, h := range handlers {
+		if (h.method == "" || h.method == r.Method) && h.path == r.URL.Path {
+			return h.fn(r)
+		}
+	}
+
+	return nil, fmt.Errorf("%s %s: %w", r.Method, r.URL, ErrNoStub)
+}
@@ -0,0 +1,138 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"golang.org/x/sync/singleflight"
+
+	"github.com/prometheus/client_golang/prometheus"
+	pph "github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+type sharedResponse struct {
+	resp     *http.Response
+	body     []byte
+	streamed bool
+}
+
+// InstrumentRoundTripperCoalesce sends one request for identical concurrent GET and HEAD requests,
+// responses over 10MB are returned to the first caller only.
+func InstrumentRoundTripperCoalesce(headers []string, counter *prometheus.CounterVec, next http.RoundTripper) pph.RoundTripperFunc {
+	var group singleflight.Group
+	headers = append(append([]string{"Authorization"}, IdentityHeaders...), headers...)
+
+	return func(r *http.Request) (*http.Response, error) {
+		if (r.Method != http.MethodGet && r.Method != http.MethodHead) || (r.Body != nil && r.Body != http.NoBody) {
+			return next.RoundTrip(r)
+		}
+
+		leader := false
+		shared := group.DoChan(coalesceKey(r, headers), func() (any, error) {
+			leader = true
+			return roundTripShared(r, next)
+		})
+
+		var res singleflight.Result
+		select {
+		case res = <-shared:
+		case <-r.Context().Done():
+			go func() {
+				// the streamed response isn't taken by the leader
+				res := <-shared
+				if resp, _ := res.Val.(*sharedResponse); leader && res.Err == nil && resp.streamed {
+					_ = resp.resp.Body.Close()
+				}
+			}()
+			return nil, r.Context().Err()
+		}
+
+		if !leader {
+			counter.WithLabelValues(r.Method).Inc()
+		}
+		if res.Err != nil {
+			return nil, res.Err
+		}
+
+		resp := res.Val.(*sharedResponse) //nolint:forcetypeassert // it's returned by roundTripShared
+		if resp.streamed {
+			if leader {
+				resp.resp.Request = r
+				return resp.resp, nil
+			}
+
+			return next.RoundTrip(r)
+		}
+
+		return resp.copy(r), nil
+	}
+}
+
+func roundTripShared(r *http.Request, next http.RoundTripper) (*sharedResponse, error) {
+	ctx, cancel := withoutCancel(r.Context()), context.CancelFunc(func() {})
+	if deadline, ok := r.Context().Deadline(); ok {
+		ctx, cancel = context.WithDeadline(ctx, deadline)
+	}
+
+	resp, err := next.RoundTrip(r.WithContext(ctx))
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	if r.Method != http.MethodHead && resp.ContentLength > defaultMaxResponseSize {
+		resp.Body = &releaseBody{ReadCloser: resp.Body, release: cancel}
+		return &sharedResponse{resp: resp, streamed: true}, nil
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, defaultMaxResponseSize+1))
+	if err != nil {
+		_ = resp.Body.Close()
+		cancel()
+		return nil, fmt.Errorf("read coalesced response: %w", err)
+	}
+
+	if len(body) > defaultMaxResponseSize {
+		resp.Body = &releaseBody{
+			ReadCloser: struct {
+				io.Reader
+				io.Closer
+			}{io.MultiReader(bytes.NewReader(body), resp.Body), resp.Body},
+			release: cancel,
+		}
+		return &sharedResponse{resp: resp, streamed: true}, nil
+	}
+
+	_ = resp.Body.Close()
+	cancel()
+
+	return &sharedResponse{resp: resp, body: body}, nil
+}
+
+func (s *sharedResponse) copy(r *http.Request) *http.Response {
+	resp := *s.resp
+	resp.Header = s.resp.Header.Clone()
+	resp.Trailer = s.resp.Trailer.Clone()
+	resp.Body = io.NopCloser(bytes.NewReader(s.body))
+	resp.ContentLength = int64(len(s.body))
+	resp.Request = r
+
+	return &resp
+}
+
+func coalesceKey(r *http.Request, headers []string) string {
+	var key strings.Builder
+	key.WriteString(r.Method)
+	key.WriteByte(' ')
+	key.WriteString(r.URL.String())
+	for _, name := range headers {
+		key.WriteByte('\n')
+		key.WriteString(strings.Join(r.Header.Values(name), ","))
+	}
+
+	return key.String()
+}
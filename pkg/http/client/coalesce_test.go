@@ -0,0 +1,179 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func TestCoalescing(t *testing.T) {
+	var (
+		requests atomic.Int32
+		release  = make(chan struct{})
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		<-release
+		_, _ = w.Write([]byte("body"))
+	}))
+	defer srv.Close()
+
+	registry := prometheus.NewRegistry()
+	c, err := NewClient("test", WithPrometheusRegisterer(registry), WithCoalescing("Authorization"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			resp, err := c.Get(srv.URL)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			defer resp.Body.Close()
+
+			if body, _ := io.ReadAll(resp.Body); string(body) != "body" {
+				t.Errorf("body = %q, want %q", body, "body")
+			}
+		}()
+	}
+
+	time.Sleep(time.Millisecond * 100)
+	close(release)
+	wg.Wait()
+
+	if requests.Load() != 1 {
+		t.Errorf("server got %d requests, want 1", requests.Load())
+	}
+
+	if got := counterValue(t, registry, "test_http_outgoing_coalesced_requests_total"); got != 4 {
+		t.Errorf("coalesced_requests_total = %v, want 4", got)
+	}
+}
+
+func TestCoalescingCancel(t *testing.T) {
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		_, _ = w.Write([]byte("body"))
+	}))
+	defer srv.Close()
+
+	c, err := NewClient("test", WithPrometheusRegisterer(prometheus.NewRegistry()), WithCoalescing())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	leader := make(chan error)
+	go func() {
+		req, _ := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL, http.NoBody)
+		_, err := c.Do(req) //nolint:bodyclose // it's canceled
+		leader <- err
+	}()
+	time.Sleep(time.Millisecond * 50)
+
+	follower := make(chan string)
+	go func() {
+		resp, err := c.Get(srv.URL)
+		if err != nil {
+			t.Error(err)
+			follower <- ""
+			return
+		}
+		defer resp.Body.Close()
+
+		body, _ := io.ReadAll(resp.Body)
+		follower <- string(body)
+	}()
+	time.Sleep(time.Millisecond * 50)
+
+	// the first caller leaves, the shared request goes on for the follower
+	cancel()
+	if err := <-leader; !errors.Is(err, context.Canceled) {
+		t.Errorf("canceled caller got %v", err)
+	}
+	close(release)
+
+	if body := <-follower; body != "body" {
+		t.Errorf("follower body = %q, want %q", body, "body")
+	}
+}
+
+func TestCoalescingLargeResponse(t *testing.T) {
+	var (
+		requests atomic.Int32
+		release  = make(chan struct{})
+		large    = strings.Repeat("a", defaultMaxResponseSize+1)
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		<-release
+		_, _ = w.Write([]byte(large))
+	}))
+	defer srv.Close()
+
+	c, err := NewClient("test", WithPrometheusRegisterer(prometheus.NewRegistry()), WithCoalescing())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			resp, err := c.Get(srv.URL)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			defer resp.Body.Close()
+
+			if body, _ := io.ReadAll(resp.Body); len(body) != len(large) {
+				t.Errorf("body of %d bytes, want %d", len(body), len(large))
+			}
+		}()
+	}
+
+	time.Sleep(time.Millisecond * 100)
+	close(release)
+	wg.Wait()
+
+	if requests.Load() != 3 {
+		t.Errorf("server got %d requests, want own requests of followers", requests.Load())
+	}
+}
+
+func counterValue(t *testing.T, registry *prometheus.Registry, name string) float64 {
+	t.Helper()
+
+	families, err := registry.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, family := range families {
+		if family.GetName() == name {
+			return family.GetMetric()[0].GetCounter().GetValue()
+		}
+	}
+
+	t.Fatalf("%s isn't registered", name)
+	return 0
+}
@@ -4,16 +4,58 @@ import (
 	"net/http"
 
 	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
 
 	"github.com/fusionmedialimited/backend-infra-libraries/v3/pkg/config"
 	"github.com/fusionmedialimited/backend-infra-libraries/v3/pkg/di"
 )
 
+type FaultConfig struct {
+	Enabled bool `env:"HTTP_CLIENT_FAULT_INJECTION" desc:"Enables fault injection by /debug/faults, for chaos testing only"`
+}
+
 func Setup(c *di.Container) {
+	config.Register(FaultConfig{}, "")
+	di.Set(c, di.OptInit(func() (conf FaultConfig, _ error) {
+		err := config.Parse(&conf, "")
+		return conf, err
+	}))
+	di.Set(c, di.OptInit(func() (*FaultInjector, error) {
+		return NewFaultInjector()
+	}))
 	di.Set(c, di.OptInit(func() (*http.Client, error) {
-		return NewClient(
-			di.GetNamed[string](c, config.AppName),
-			WithTraceProvider(di.Get[trace.TracerProvider](c)),
-		)
+		return NewClient(di.GetNamed[string](c, config.AppName), diOptions(c)...)
 	}))
 }
+
+// SetupNamed registers EnvConfig, *http.Client and *API of each downstream by its name.
+func SetupNamed(c *di.Container, names ...string) {
+	for _, name := range names {
+		name := name
+		config.Register(EnvConfig{}, EnvPrefix(name))
+		di.SetNamed(c, name, di.OptInit(func() (EnvConfig, error) {
+			return EnvConfigFromEnv(name)
+		}))
+		di.SetNamed(c, name, di.OptInit(func() (*http.Client, error) {
+			opts := append(di.GetNamed[EnvConfig](c, name).Options(name), diOptions(c)...)
+			return NewClient(di.GetNamed[string](c, config.AppName), opts...)
+		}))
+		di.SetNamed(c, name, di.OptInit(func() (*API, error) {
+			return NewAPI(di.GetNamed[*http.Client](c, name), di.GetNamed[EnvConfig](c, name).BaseURL), nil
+		}))
+	}
+}
+
+func diOptions(c *di.Container) []OptionFunc {
+	// clients share the registry, so the default one is labeled by empty service as well
+	opts := []OptionFunc{
+		WithTraceProvider(di.Get[trace.TracerProvider](c)),
+		WithLogger(di.Get[*zap.Logger](c)),
+		WithServiceLabel(),
+	}
+	if di.Get[FaultConfig](c).Enabled {
+		opts = append(opts, WithFaultInjection(di.Get[*FaultInjector](c)))
+	}
+
+	return opts
+}
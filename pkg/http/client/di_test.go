@@ -0,0 +1,28 @@
+package client
+
+import (
+	"net/http"
+	"testing"
+
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+
+	"github.com/fusionmedialimited/backend-infra-libraries/v3/pkg/config"
+	"github.com/fusionmedialimited/backend-infra-libraries/v3/pkg/di"
+)
+
+func TestSetupDefaultAndNamed(t *testing.T) {
+	t.Setenv("HTTP_CLIENT_USERS_BASE_URL", "http://users.svc")
+
+	c := di.New()
+	Setup(c)
+	SetupNamed(c, "users")
+	di.SetNamed(c, config.AppName, di.OptInit(func() (string, error) { return "ditest", nil }))
+	di.Set(c, di.OptInit(func() (*zap.Logger, error) { return zap.NewNop(), nil }))
+	di.Set(c, di.OptInit(func() (trace.TracerProvider, error) { return trace.NewNoopTracerProvider(), nil }))
+
+	// both are registered by the default registerer with the same labels
+	if di.Get[*http.Client](c) == di.GetNamed[*http.Client](c, "users") {
+		t.Error("default and named clients are the same")
+	}
+}
@@ -0,0 +1,87 @@
+package client
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/fusionmedialimited/backend-infra-libraries/v3/pkg/config"
+	"github.com/fusionmedialimited/backend-infra-libraries/v3/pkg/http/signature"
+)
+
+// EnvConfig configures named downstream client by `HTTP_CLIENT_<NAME>_*` variables, see SetupNamed.
+type EnvConfig struct {
+	BaseURL          string        `env:"BASE_URL"                                               desc:"Base URL of the downstream API"`
+	Timeout          time.Duration `env:"TIMEOUT"           envDefault:"3s"    validate:"min=0s" desc:"Overall timeout of a request including retries"`
+	MaxIdlePerHost   int           `env:"MAX_IDLE_PER_HOST" envDefault:"100"   validate:"min=0"  desc:"Max idle connections to the downstream host"`
+	RetryAttempts    int           `env:"RETRY_ATTEMPTS"    envDefault:"1"     validate:"min=1"  desc:"Max attempts of idempotent requests, 1 disables retries"`
+	RetryMinBackoff  time.Duration `env:"RETRY_MIN_BACKOFF" envDefault:"100ms" validate:"min=0s" desc:"Backoff before the first retry, it's doubled for next ones"`
+	RetryMaxBackoff  time.Duration `env:"RETRY_MAX_BACKOFF" envDefault:"2s"    validate:"min=0s" desc:"Max backoff between retries"`
+	TLSCertFile      string        `env:"TLS_CERT_FILE"                                          desc:"PEM client certificate for mTLS, reloaded on rotation"`
+	TLSKeyFile       string        `env:"TLS_KEY_FILE"                                           desc:"PEM key of the client certificate"`
+	TLSCAFile        string        `env:"TLS_CA_FILE"                                            desc:"PEM CA of the downstream, system roots are used if it's empty"`
+	TLSServerName    string        `env:"TLS_SERVER_NAME"                                        desc:"Name verified in the downstream certificate, the host is verified if it's empty"`
+	PropagateHeaders []string      `env:"PROPAGATE_HEADERS"                                      desc:"Headers of incoming requests copied to outgoing ones, request ID, tenant, user and locale by default"`
+	OAuth2TokenURL   string        `env:"OAUTH2_TOKEN_URL"                                       desc:"Token URL of OAuth2 client credentials grant, empty disables it"`
+	OAuth2ClientID   string        `env:"OAUTH2_CLIENT_ID"                                       desc:"OAuth2 client ID"`
+	OAuth2Secret     string        `env:"OAUTH2_CLIENT_SECRET" secret:"true"                     desc:"OAuth2 client secret"`
+	OAuth2Scopes     []string      `env:"OAUTH2_SCOPES"                                          desc:"Comma separated OAuth2 scopes"`
+	SigningKeyID     string        `env:"SIGNING_KEY_ID"                                         desc:"Key ID of HMAC request signatures, empty disables signing"`
+	SigningKey       string        `env:"SIGNING_KEY" secret:"true"                              desc:"HMAC key of request signatures"`
+}
+
+func (c EnvConfig) Validate() error {
+	if (c.TLSCertFile == "") != (c.TLSKeyFile == "") {
+		return config.NewFieldError("TLSKeyFile", errors.New("must be set with TLS_CERT_FILE"))
+	}
+	if c.OAuth2TokenURL != "" && c.OAuth2ClientID == "" {
+		return config.NewFieldError("OAuth2ClientID", errors.New("must be set with OAUTH2_TOKEN_URL"))
+	}
+	if c.SigningKeyID != "" && c.SigningKey == "" {
+		return config.NewFieldError("SigningKey", errors.New("must be set with SIGNING_KEY_ID"))
+	}
+
+	return nil
+}
+
+func EnvPrefix(name string) string {
+	return "HTTP_CLIENT_" + strings.NewReplacer("-", "_", ".", "_").Replace(strings.ToUpper(name)) + "_"
+}
+
+func EnvConfigFromEnv(name string) (conf EnvConfig, _ error) {
+	err := config.Parse(&conf, EnvPrefix(name))
+	return conf, err
+}
+
+// Options returns client options of the config, the name is used as a service name.
+func (c EnvConfig) Options(name string) []OptionFunc {
+	opts := []OptionFunc{
+		WithServiceName(strings.ToLower(name)),
+		WithTimeout(c.Timeout),
+		WithMaxIdleConnectionsPerHost(c.MaxIdlePerHost),
+		WithHeaderPropagation(c.PropagateHeaders...),
+	}
+	if c.TLSCertFile != "" || c.TLSCAFile != "" {
+		opts = append(opts, WithTLS(TLSConfig{
+			CertFile:   c.TLSCertFile,
+			KeyFile:    c.TLSKeyFile,
+			CAFile:     c.TLSCAFile,
+			ServerName: c.TLSServerName,
+		}))
+	}
+	if c.OAuth2TokenURL != "" {
+		opts = append(opts, WithOAuth2ClientCredentials(c.OAuth2TokenURL, c.OAuth2ClientID, c.OAuth2Secret, c.OAuth2Scopes...))
+	}
+	if c.SigningKeyID != "" {
+		opts = append(opts, WithRequestSigner(signature.HMACSigner{KeyID: c.SigningKeyID, Key: []byte(c.SigningKey)}))
+	}
+	if c.RetryAttempts > 1 {
+		opts = append(opts, WithRetry(RetryPolicy{
+			MaxAttempts: c.RetryAttempts,
+			MinBackoff:  c.RetryMinBackoff,
+			MaxBackoff:  c.RetryMaxBackoff,
+		}))
+	}
+
+	return opts
+}
@@ -0,0 +1,73 @@
+package client
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestEnvConfigFromEnv(t *testing.T) {
+	t.Setenv("HTTP_CLIENT_USERS_BASE_URL", "https://users.svc")
+	t.Setenv("HTTP_CLIENT_USERS_RETRY_ATTEMPTS", "3")
+
+	conf, err := EnvConfigFromEnv("users")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if conf.BaseURL != "https://users.svc" || conf.Timeout != 3*time.Second || conf.RetryAttempts != 3 {
+		t.Errorf("EnvConfigFromEnv() = %+v", conf)
+	}
+
+	var config Config
+	for _, opt := range conf.Options("users") {
+		opt(&config)
+	}
+
+	if config.ServiceName != "users" || config.Retry == nil || config.Retry.MaxAttempts != 3 {
+		t.Errorf("Options() = %+v", config)
+	}
+
+	t.Setenv("HTTP_CLIENT_USERS_RETRY_ATTEMPTS", "0")
+	if _, err = EnvConfigFromEnv("users"); err == nil {
+		t.Error("EnvConfigFromEnv() = nil error for 0 attempts")
+	}
+}
+
+func TestEnvConfigSecretFiles(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "secret")
+	if err := os.WriteFile(path, []byte("s3cret\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("HTTP_CLIENT_BILLING_OAUTH2_TOKEN_URL", "https://auth.svc/token")
+	t.Setenv("HTTP_CLIENT_BILLING_OAUTH2_CLIENT_ID", "billing")
+	t.Setenv("HTTP_CLIENT_BILLING_OAUTH2_CLIENT_SECRET_FILE", path)
+	t.Setenv("HTTP_CLIENT_BILLING_SIGNING_KEY_ID", "k1")
+	t.Setenv("HTTP_CLIENT_BILLING_SIGNING_KEY_FILE", path)
+
+	conf, err := EnvConfigFromEnv("billing")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if conf.OAuth2Secret != "s3cret" || conf.SigningKey != "s3cret" {
+		t.Errorf("EnvConfigFromEnv() = %+v, want secrets from file", conf)
+	}
+
+	var config Config
+	for _, opt := range conf.Options("billing") {
+		opt(&config)
+	}
+
+	if config.OAuth2 == nil || config.OAuth2.ClientSecret != "s3cret" || config.Signer == nil {
+		t.Errorf("Options() = %+v", config)
+	}
+}
+
+func TestEnvPrefix(t *testing.T) {
+	if got, want := EnvPrefix("user-profile.v2"), "HTTP_CLIENT_USER_PROFILE_V2_"; got != want {
+		t.Errorf("EnvPrefix() = %q, want %q", got, want)
+	}
+}
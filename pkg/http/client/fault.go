@@ -0,0 +1,238 @@
+package client
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	pph "github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"github.com/fusionmedialimited/backend-infra-libraries/v3/pkg/primitives"
+)
+
+const maxFaultRulesSize = 1 << 20
+
+var (
+	ErrInjectedFault = errors.New("injected fault")
+	ErrInvalidFault  = errors.New("invalid fault rule")
+)
+
+type (
+	// FaultRule injects faults into Percentage of requests, empty Host, Method and Path match any.
+	FaultRule struct {
+		Host         string        `json:"host,omitempty"`
+		Method       string        `json:"method,omitempty"`
+		Path         string        `json:"path,omitempty"`
+		Percentage   float64       `json:"percentage"`
+		Latency      time.Duration `json:"-"`
+		Status       int           `json:"status,omitempty"`
+		Reset        bool          `json:"reset,omitempty"`
+		TruncateBody bool          `json:"truncate_body,omitempty"`
+	}
+
+	faultRuleJSON struct {
+		faultRule
+		Latency string `json:"latency,omitempty"`
+	}
+
+	faultRule FaultRule
+
+	// FaultInjector serves rules by GET, PUT and DELETE.
+	FaultInjector struct {
+		mu    sync.RWMutex
+		rules []FaultRule
+	}
+
+	truncatedBody struct {
+		io.Reader
+		io.Closer
+	}
+
+	errReader struct{ err error }
+)
+
+func (r FaultRule) Validate() error {
+	switch {
+	case r.Percentage < 0 || r.Percentage > 100:
+		return fmt.Errorf("%w: percentage %v isn't in 0..100", ErrInvalidFault, r.Percentage)
+	case r.Latency < 0:
+		return fmt.Errorf("%w: negative latency", ErrInvalidFault)
+	case r.Status != 0 && (r.Status < 100 || r.Status > 599):
+		return fmt.Errorf("%w: status %d", ErrInvalidFault, r.Status)
+	case r.Reset && r.Status != 0:
+		return fmt.Errorf("%w: reset with status %d", ErrInvalidFault, r.Status)
+	default:
+		return nil
+	}
+}
+
+func (r FaultRule) MarshalJSON() ([]byte, error) {
+	data := faultRuleJSON{faultRule: faultRule(r)}
+	if r.Latency > 0 {
+		data.Latency = r.Latency.String()
+	}
+
+	return primitives.MarshalJSON(data)
+}
+
+func (r *FaultRule) UnmarshalJSON(data []byte) error {
+	var rule faultRuleJSON
+	if err := primitives.UnmarshalJSON(data, &rule); err != nil {
+		return err
+	}
+
+	*r = FaultRule(rule.faultRule)
+	if rule.Latency != "" {
+		latency, err := time.ParseDuration(rule.Latency)
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrInvalidFault, err)
+		}
+		r.Latency = latency
+	}
+
+	return nil
+}
+
+func NewFaultInjector(rules ...FaultRule) (*FaultInjector, error) {
+	injector := new(FaultInjector)
+	return injector, injector.SetRules(rules...)
+}
+
+func (f *FaultInjector) Rules() []FaultRule {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	return append([]FaultRule(nil), f.rules...)
+}
+
+func (f *FaultInjector) SetRules(rules ...FaultRule) error {
+	for _, rule := range rules {
+		if err := rule.Validate(); err != nil {
+			return err
+		}
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.rules = append([]FaultRule(nil), rules...)
+	return nil
+}
+
+func (f *FaultInjector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPut:
+		data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxFaultRulesSize))
+		if tooLarge := new(http.MaxBytesError); errors.As(err, &tooLarge) {
+			http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+			return
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		var rules []FaultRule
+		if err = primitives.UnmarshalJSON(data, &rules); err == nil {
+			err = f.SetRules(rules...)
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	case http.MethodDelete:
+		_ = f.SetRules()
+	default:
+		w.Header().Set("Allow", "GET, PUT, DELETE")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	data, err := primitives.MarshalJSON(f.Rules())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", MIMEApplicationJSON)
+	_, _ = w.Write(data)
+}
+
+func (f *FaultInjector) match(r *http.Request) (FaultRule, bool) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	for _, rule := range f.rules {
+		if (rule.Host == "" || rule.Host == r.URL.Host || rule.Host == r.URL.Hostname()) &&
+			(rule.Method == "" || strings.EqualFold(rule.Method, r.Method)) &&
+			strings.HasPrefix(r.URL.Path, rule.Path) &&
+			rand.Float64()*100 < rule.Percentage { //nolint:gosec // sampling doesn't need crypto
+			return rule, true
+		}
+	}
+
+	return FaultRule{}, false
+}
+
+// InstrumentRoundTripperFaults injects faults of the matched rules, resets wrap syscall.ECONNRESET.
+func InstrumentRoundTripperFaults(injector *FaultInjector, next http.RoundTripper) pph.RoundTripperFunc {
+	return func(r *http.Request) (*http.Response, error) {
+		rule, ok := injector.match(r)
+		if !ok {
+			return next.RoundTrip(r)
+		}
+
+		if rule.Latency > 0 {
+			if err := sleep(r.Context(), rule.Latency); err != nil {
+				return nil, err
+			}
+		}
+
+		switch {
+		case rule.Reset:
+			return nil, fmt.Errorf("%w: %w", ErrInjectedFault, syscall.ECONNRESET)
+		case rule.Status != 0:
+			return &http.Response{
+				Status:     fmt.Sprintf("%d %s", rule.Status, http.StatusText(rule.Status)),
+				StatusCode: rule.Status,
+				Proto:      "HTTP/1.1",
+				ProtoMajor: 1,
+				ProtoMinor: 1,
+				Header:     http.Header{"X-Injected-Fault": {"status"}},
+				Body:       http.NoBody,
+				Request:    r,
+			}, nil
+		}
+
+		resp, err := next.RoundTrip(r)
+		if err != nil || !rule.TruncateBody {
+			return resp, err
+		}
+
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			_ = resp.Body.Close()
+			return nil, err
+		}
+
+		resp.Body = truncatedBody{
+			Reader: io.MultiReader(bytes.NewReader(body[:len(body)/2]), errReader{io.ErrUnexpectedEOF}),
+			Closer: resp.Body,
+		}
+		resp.ContentLength = -1
+
+		return resp, nil
+	}
+}
+
+func (r errReader) Read([]byte) (int, error) {
+	return 0, r.err
+}
@@ -0,0 +1,148 @@
+package client
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func TestFaultInjection(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("0123456789"))
+	}))
+	defer srv.Close()
+
+	injector, err := NewFaultInjector()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c, err := NewClient("test", WithPrometheusRegisterer(prometheus.NewRegistry()), WithFaultInjection(injector))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	admin := httptest.NewServer(injector)
+	defer admin.Close()
+
+	put := func(rules string) {
+		t.Helper()
+
+		req, _ := http.NewRequest(http.MethodPut, admin.URL, strings.NewReader(rules))
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_ = resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("PUT %s: status = %d", rules, resp.StatusCode)
+		}
+	}
+
+	put(`[{"path":"/status","percentage":100,"status":503},
+		{"path":"/reset","percentage":100,"reset":true},
+		{"path":"/truncate","percentage":100,"truncate_body":true},
+		{"path":"/slow","percentage":100,"latency":"50ms"},
+		{"path":"/never","percentage":0,"reset":true}]`)
+
+	resp, err := c.Get(srv.URL + "/status")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+
+	if resp.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("status = %d, want %d", resp.StatusCode, http.StatusServiceUnavailable)
+	}
+
+	if _, err := c.Get(srv.URL + "/reset"); !errors.Is(err, ErrInjectedFault) || !errors.Is(err, syscall.ECONNRESET) {
+		t.Errorf("reset: err = %v", err)
+	}
+
+	resp, err = c.Get(srv.URL + "/truncate")
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, err := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if string(body) != "01234" || !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("truncate: body = %q, err = %v", body, err)
+	}
+
+	start := time.Now()
+	if resp, err = c.Get(srv.URL + "/slow"); err != nil || time.Since(start) < time.Millisecond*50 {
+		t.Errorf("slow: err = %v after %v", err, time.Since(start))
+	} else {
+		_ = resp.Body.Close()
+	}
+
+	if resp, err = c.Get(srv.URL + "/never"); err != nil {
+		t.Errorf("never: err = %v", err)
+	} else {
+		_ = resp.Body.Close()
+	}
+
+	req, _ := http.NewRequest(http.MethodDelete, admin.URL, http.NoBody)
+	if resp, err = http.DefaultClient.Do(req); err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+
+	if rules := injector.Rules(); len(rules) != 0 {
+		t.Errorf("rules = %v after DELETE, want none", rules)
+	}
+}
+
+func TestFaultRuleJSON(t *testing.T) {
+	injector := new(FaultInjector)
+	rec := httptest.NewRecorder()
+	injector.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/", strings.NewReader(`[{"percentage":10,"latency":"1.5s"}]`)))
+
+	if want := `[{"percentage":10,"latency":"1.5s"}]`; rec.Body.String() != want {
+		t.Errorf("rules = %s, want %s", rec.Body, want)
+	}
+
+	rec = httptest.NewRecorder()
+	injector.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/", strings.NewReader(`[{"percentage":110}]`)))
+
+	if rec.Code != http.StatusBadRequest || len(injector.Rules()) != 1 {
+		t.Errorf("invalid rule: status = %d, rules = %v", rec.Code, injector.Rules())
+	}
+
+	rec = httptest.NewRecorder()
+	injector.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/", strings.NewReader(`[{"percentage":10,"reset":true,"status":503}]`)))
+
+	if rec.Code != http.StatusBadRequest || len(injector.Rules()) != 1 {
+		t.Errorf("reset with status: status = %d, rules = %v", rec.Code, injector.Rules())
+	}
+
+	rec = httptest.NewRecorder()
+	injector.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/", strings.NewReader(strings.Repeat(" ", maxFaultRulesSize+1))))
+
+	if rec.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("large body: status = %d, want %d", rec.Code, http.StatusRequestEntityTooLarge)
+	}
+}
+
+func TestFaultRuleFallThrough(t *testing.T) {
+	injector, err := NewFaultInjector(
+		FaultRule{Path: "/", Status: http.StatusServiceUnavailable},
+		FaultRule{Path: "/", Percentage: 100, Status: http.StatusTeapot},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/users", http.NoBody)
+	if rule, ok := injector.match(req); !ok || rule.Status != http.StatusTeapot {
+		t.Errorf("match = %v, %v, want the second rule after the first roll failed", rule, ok)
+	}
+}
@@ -0,0 +1,186 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	pph "github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+type (
+	// HedgePolicy zero fields are taken from DefaultHedgePolicy.
+	HedgePolicy struct {
+		Quantile     float64
+		InitialDelay time.Duration
+		MinDelay     time.Duration
+		MinSamples   int
+		Window       int
+	}
+
+	latencies struct {
+		policy HedgePolicy
+		delay  atomic.Int64
+
+		mu      sync.Mutex
+		samples []time.Duration
+		next    int
+		total   int
+	}
+
+	hedgeResult struct {
+		resp    *http.Response
+		err     error
+		attempt int
+	}
+)
+
+func DefaultHedgePolicy() HedgePolicy {
+	const (
+		defaultQuantile     = 0.95
+		defaultInitialDelay = time.Millisecond * 100
+		defaultMinDelay     = time.Millisecond * 10
+		defaultMinSamples   = 20
+		defaultWindow       = 1000
+	)
+
+	return HedgePolicy{
+		Quantile:     defaultQuantile,
+		InitialDelay: defaultInitialDelay,
+		MinDelay:     defaultMinDelay,
+		MinSamples:   defaultMinSamples,
+		Window:       defaultWindow,
+	}
+}
+
+func (p HedgePolicy) withDefaults() HedgePolicy {
+	def := DefaultHedgePolicy()
+	if p.Quantile <= 0 || p.Quantile > 1 {
+		p.Quantile = def.Quantile
+	}
+	if p.InitialDelay <= 0 {
+		p.InitialDelay = def.InitialDelay
+	}
+	if p.MinDelay <= 0 {
+		p.MinDelay = def.MinDelay
+	}
+	if p.MinSamples <= 0 {
+		p.MinSamples = def.MinSamples
+	}
+	if p.Window <= 0 {
+		p.Window = def.Window
+	}
+
+	return p
+}
+
+// InstrumentRoundTripperHedge sends a delayed second attempt of idempotent requests, the first response wins.
+func InstrumentRoundTripperHedge(policy HedgePolicy, counter *prometheus.CounterVec, next http.RoundTripper) pph.RoundTripperFunc {
+	policy = policy.withDefaults()
+	observed := &latencies{policy: policy, samples: make([]time.Duration, policy.Window)}
+	observed.delay.Store(int64(policy.InitialDelay))
+
+	return func(r *http.Request) (*http.Response, error) {
+		if !isRetriable(r) {
+			return next.RoundTrip(r)
+		}
+
+		var (
+			results = make(chan hedgeResult, 2)
+			cancels = make([]context.CancelFunc, 0, 2)
+		)
+		send := func(req *http.Request) {
+			ctx, cancel := context.WithCancel(r.Context())
+			cancels = append(cancels, cancel)
+
+			attempt := len(cancels) - 1
+			go func() {
+				start := time.Now()
+				resp, err := next.RoundTrip(req.WithContext(ctx))
+				if err == nil {
+					observed.observe(time.Since(start))
+				}
+				results <- hedgeResult{resp: resp, err: err, attempt: attempt}
+			}()
+		}
+
+		send(r)
+		timer := time.NewTimer(time.Duration(observed.delay.Load()))
+		defer timer.Stop()
+
+		for pending := 1; ; {
+			select {
+			case <-timer.C:
+				body, err := rewind(r)
+				if err != nil {
+					continue
+				}
+
+				hedged := r.Clone(r.Context())
+				hedged.Body = body
+				counter.WithLabelValues(r.Method).Inc()
+				send(hedged)
+				pending++
+			case res := <-results:
+				pending--
+				if res.err != nil && pending > 0 {
+					continue
+				}
+
+				// the loser is canceled and its response is closed in background
+				for attempt, cancel := range cancels {
+					if attempt != res.attempt {
+						cancel()
+					}
+				}
+				go func(pending int) {
+					for ; pending > 0; pending-- {
+						if loser := <-results; loser.err == nil {
+							_ = loser.resp.Body.Close()
+						}
+					}
+				}(pending)
+
+				if res.err != nil {
+					cancels[res.attempt]()
+					return nil, res.err
+				}
+				res.resp.Body = &releaseBody{ReadCloser: res.resp.Body, release: cancels[res.attempt]}
+
+				return res.resp, nil
+			}
+		}
+	}
+}
+
+func (l *latencies) observe(d time.Duration) {
+	const recalculateEvery = 16
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.samples[l.next] = d
+	l.next = (l.next + 1) % len(l.samples)
+	l.total++
+
+	if l.total < l.policy.MinSamples || l.total%recalculateEvery != 0 {
+		return
+	}
+
+	count := len(l.samples)
+	if l.total < count {
+		count = l.total
+	}
+	sorted := append([]time.Duration(nil), l.samples[:count]...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	delay := sorted[int(float64(len(sorted)-1)*l.policy.Quantile)]
+	if delay < l.policy.MinDelay {
+		delay = l.policy.MinDelay
+	}
+	l.delay.Store(int64(delay))
+}
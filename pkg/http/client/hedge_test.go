@@ -0,0 +1,82 @@
+package client
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func TestHedging(t *testing.T) {
+	var (
+		requests atomic.Int32
+		canceled = make(chan struct{})
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) == 1 {
+			<-r.Context().Done()
+			close(canceled)
+			return
+		}
+		_, _ = w.Write([]byte("hedged"))
+	}))
+	defer srv.Close()
+
+	policy := DefaultHedgePolicy()
+	policy.InitialDelay = time.Millisecond * 20
+
+	registry := prometheus.NewRegistry()
+	c, err := NewClient("test", WithPrometheusRegisterer(registry), WithHedging(policy), WithTimeout(time.Second))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := c.Get(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+
+	if string(body) != "hedged" {
+		t.Errorf("body = %q, want %q", body, "hedged")
+	}
+
+	select {
+	case <-canceled:
+	case <-time.After(time.Second):
+		t.Error("slow attempt isn't canceled")
+	}
+
+	if got := counterValue(t, registry, "test_http_outgoing_hedged_requests_total"); got != 1 {
+		t.Errorf("hedged_requests_total = %v, want 1", got)
+	}
+}
+
+func TestHedgingZeroPolicy(t *testing.T) {
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) { requests.Add(1) }))
+	defer srv.Close()
+
+	c, err := NewClient("test", WithPrometheusRegisterer(prometheus.NewRegistry()), WithHedging(HedgePolicy{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 10; i++ {
+		resp, err := c.Get(srv.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_ = resp.Body.Close()
+	}
+
+	// the initial delay is taken from defaults, so fast requests aren't hedged
+	if requests.Load() != 10 {
+		t.Errorf("server got %d requests, want 10", requests.Load())
+	}
+}
@@ -0,0 +1,152 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/fusionmedialimited/backend-infra-libraries/v3/pkg/http/problem"
+	"github.com/fusionmedialimited/backend-infra-libraries/v3/pkg/primitives"
+)
+
+const (
+	MIMEApplicationJSON = "application/json"
+
+	defaultMaxResponseSize = 10 << 20
+	errorExcerptSize       = 1 << 10
+)
+
+var ErrResponseTooLarge = errors.New("response is too large")
+
+type (
+	API struct {
+		client          *http.Client
+		baseURL         string
+		header          http.Header
+		maxResponseSize int64
+	}
+
+	APIOptionFunc = func(api *API)
+
+	HTTPError struct {
+		StatusCode int
+		Body       []byte // excerpt of the body
+		Problem    *problem.Problem
+	}
+)
+
+func NewAPI(httpClient *http.Client, baseURL string, opts ...APIOptionFunc) *API {
+	api := &API{
+		client:          httpClient,
+		baseURL:         strings.TrimRight(baseURL, "/"),
+		header:          make(http.Header),
+		maxResponseSize: defaultMaxResponseSize,
+	}
+	for _, opt := range opts {
+		opt(api)
+	}
+
+	return api
+}
+
+func WithDefaultHeader(key, value string) APIOptionFunc {
+	return func(api *API) {
+		api.header.Add(key, value)
+	}
+}
+
+func WithMaxResponseSize(size int64) APIOptionFunc {
+	return func(api *API) {
+		api.maxResponseSize = size
+	}
+}
+
+// DoJSON decodes 2xx response into Resp, other statuses are returned as *HTTPError.
+func DoJSON[Req, Resp any](ctx context.Context, api *API, method, path string, req Req) (resp Resp, _ error) {
+	data, err := primitives.MarshalJSON(req)
+	if err != nil {
+		return resp, err
+	}
+
+	return do[Resp](ctx, api, method, path, bytes.NewReader(data))
+}
+
+func GetJSON[Resp any](ctx context.Context, api *API, path string) (Resp, error) {
+	return do[Resp](ctx, api, http.MethodGet, path, nil)
+}
+
+func do[Resp any](ctx context.Context, api *API, method, path string, body io.Reader) (resp Resp, _ error) {
+	r, err := http.NewRequestWithContext(ctx, method, api.baseURL+path, body)
+	if err != nil {
+		return resp, fmt.Errorf("new request: %w", err)
+	}
+
+	// values are copied, so changes of the request don't leak into defaults
+	for key, vals := range api.header {
+		r.Header[key] = append([]string(nil), vals...)
+	}
+	r.Header.Set("Accept", MIMEApplicationJSON)
+	if body != nil {
+		r.Header.Set("Content-Type", MIMEApplicationJSON)
+	}
+
+	res, err := api.client.Do(r)
+	if err != nil {
+		return resp, err
+	}
+	defer res.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(res.Body, api.maxResponseSize+1))
+	if err != nil {
+		return resp, fmt.Errorf("read response: %w", err)
+	}
+
+	if res.StatusCode < http.StatusOK || res.StatusCode >= http.StatusMultipleChoices {
+		return resp, newHTTPError(res, data)
+	}
+
+	if int64(len(data)) > api.maxResponseSize {
+		return resp, fmt.Errorf("%w: over %d bytes", ErrResponseTooLarge, api.maxResponseSize)
+	}
+
+	if len(data) == 0 {
+		return resp, nil
+	}
+
+	return resp, primitives.UnmarshalJSON(data, &resp)
+}
+
+func newHTTPError(res *http.Response, body []byte) *HTTPError {
+	err := &HTTPError{StatusCode: res.StatusCode, Body: body}
+	if len(err.Body) > errorExcerptSize {
+		err.Body = err.Body[:errorExcerptSize]
+	}
+
+	if mediaType, _, _ := mime.ParseMediaType(res.Header.Get("Content-Type")); mediaType == problem.MIMEApplicationProblem {
+		var p problem.Problem
+		if primitives.UnmarshalJSON(body, &p) == nil {
+			err.Problem = &p
+		}
+	}
+
+	return err
+}
+
+func (e *HTTPError) Error() string {
+	msg := fmt.Sprintf("http status %d", e.StatusCode)
+	switch {
+	case e.Problem != nil && e.Problem.Detail != "":
+		return msg + ": " + e.Problem.Title + ": " + e.Problem.Detail
+	case e.Problem != nil:
+		return msg + ": " + e.Problem.Title
+	case len(e.Body) > 0:
+		return msg + ": " + string(e.Body)
+	default:
+		return msg
+	}
+}
@@ -0,0 +1,109 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	pph "github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"github.com/fusionmedialimited/backend-infra-libraries/v3/pkg/http/problem"
+)
+
+type user struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+func newTestAPI(t *testing.T, handler http.HandlerFunc, opts ...APIOptionFunc) *API {
+	t.Helper()
+
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+
+	c, err := NewClient("test", WithPrometheusRegisterer(prometheus.NewRegistry()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return NewAPI(c, srv.URL+"/v1/", opts...)
+}
+
+func TestDoJSON(t *testing.T) {
+	api := newTestAPI(t, func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if r.URL.Path != "/v1/users" || r.Header.Get("X-Api-Key") != "key" || string(body) != `{"id":0,"name":"john"}` {
+			t.Errorf("request %s %v %s", r.URL.Path, r.Header, body)
+		}
+
+		w.Header().Set("Content-Type", MIMEApplicationJSON)
+		_, _ = w.Write([]byte(`{"id":1,"name":"john"}`))
+	}, WithDefaultHeader("X-Api-Key", "key"))
+
+	got, err := DoJSON[user, user](context.Background(), api, http.MethodPost, "/users", user{Name: "john"})
+	if err != nil || got != (user{ID: 1, Name: "john"}) {
+		t.Errorf("DoJSON() = %v, %v", got, err)
+	}
+}
+
+func TestGetJSONErrors(t *testing.T) {
+	api := newTestAPI(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/missing":
+			w.Header().Set("Content-Type", problem.MIMEApplicationProblem)
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"title":"Not Found","status":404,"detail":"no user 2"}`))
+		case "/v1/large":
+			_, _ = w.Write([]byte(`{"name":"` + strings.Repeat("a", 100) + `"}`))
+		}
+	}, WithMaxResponseSize(64))
+
+	_, err := GetJSON[user](context.Background(), api, "/missing")
+	var httpErr *HTTPError
+	if !errors.As(err, &httpErr) || httpErr.StatusCode != http.StatusNotFound ||
+		httpErr.Problem == nil || httpErr.Problem.Detail != "no user 2" {
+		t.Errorf("GetJSON() = %v, want HTTPError with problem", err)
+	}
+
+	if _, err = GetJSON[user](context.Background(), api, "/large"); !errors.Is(err, ErrResponseTooLarge) {
+		t.Errorf("GetJSON() = %v, want %v", err, ErrResponseTooLarge)
+	}
+}
+
+func TestDefaultHeaderIsolation(t *testing.T) {
+	transport := pph.RoundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		if values := r.Header.Values("X-Api-Key"); len(values) != 1 {
+			t.Errorf("X-Api-Key = %v, want the default only", values)
+		}
+
+		r.Header["X-Api-Key"][0] = "changed"
+		r.Header.Add("X-Api-Key", "added")
+
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{},
+			Body:       io.NopCloser(strings.NewReader(`{}`)),
+			Request:    r,
+		}, nil
+	})
+	c, err := NewClient("test", WithPrometheusRegisterer(prometheus.NewRegistry()), WithTransport(transport))
+	if err != nil {
+		t.Fatal(err)
+	}
+	api := NewAPI(c, "http://api.test", WithDefaultHeader("X-Api-Key", "key"))
+
+	for i := 0; i < 2; i++ {
+		if _, err := GetJSON[user](context.Background(), api, "/users/1"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if got := api.header.Values("X-Api-Key"); len(got) != 1 || got[0] != "key" {
+		t.Errorf("default X-Api-Key = %v, want [key]", got)
+	}
+}
@@ -0,0 +1,140 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+
+	"github.com/prometheus/client_golang/prometheus"
+	pph "github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// AnyHost limits hosts without own limits.
+const AnyHost = "*"
+
+var ErrLimitExceeded = errors.New("client limit exceeded")
+
+type (
+	Limit struct {
+		RPS           float64
+		Burst         int
+		MaxConcurrent int
+	}
+
+	hostLimiter struct {
+		host  string
+		rate  *rate.Limiter
+		slots chan struct{}
+	}
+
+	limitMetrics struct {
+		wait       *prometheus.HistogramVec
+		rejections *prometheus.CounterVec
+	}
+
+	releaseBody struct {
+		io.ReadCloser
+		once    sync.Once
+		release func()
+	}
+)
+
+// InstrumentRoundTripperLimit holds concurrency slot till the response body is closed.
+func InstrumentRoundTripperLimit(
+	limits map[string]Limit,
+	failFast bool,
+	metrics limitMetrics,
+	next http.RoundTripper,
+) pph.RoundTripperFunc {
+	limiters := make(map[string]*hostLimiter, len(limits))
+	for host, limit := range limits {
+		limiter := &hostLimiter{host: host}
+		if limit.RPS > 0 {
+			// zero burst rejects all requests
+			burst := limit.Burst
+			if burst < 1 {
+				burst = 1
+			}
+			limiter.rate = rate.NewLimiter(rate.Limit(limit.RPS), burst)
+		}
+		if limit.MaxConcurrent > 0 {
+			limiter.slots = make(chan struct{}, limit.MaxConcurrent)
+		}
+		limiters[host] = limiter
+	}
+
+	return func(r *http.Request) (*http.Response, error) {
+		limiter, ok := limiters[r.URL.Host]
+		if !ok {
+			if limiter, ok = limiters[r.URL.Hostname()]; !ok {
+				if limiter, ok = limiters[AnyHost]; !ok {
+					return next.RoundTrip(r)
+				}
+			}
+		}
+
+		release, err := limiter.acquire(r, failFast, metrics)
+		if err != nil {
+			return nil, err
+		}
+
+		resp, err := next.RoundTrip(r)
+		if err != nil {
+			release()
+			return nil, err
+		}
+
+		resp.Body = &releaseBody{ReadCloser: resp.Body, release: release}
+		return resp, nil
+	}
+}
+
+func (l *hostLimiter) acquire(r *http.Request, failFast bool, metrics limitMetrics) (release func(), err error) {
+	const kindRate, kindConcurrency = "rate", "concurrency"
+
+	release = func() {}
+	if l.rate != nil {
+		start := time.Now()
+		if failFast && !l.rate.Allow() || !failFast && l.rate.Wait(r.Context()) != nil {
+			metrics.rejections.WithLabelValues(l.host, kindRate).Inc()
+			return nil, fmt.Errorf("%s rate: %w", l.host, ErrLimitExceeded)
+		}
+		metrics.wait.WithLabelValues(l.host, kindRate).Observe(time.Since(start).Seconds())
+	}
+
+	if l.slots != nil {
+		start := time.Now()
+		if failFast {
+			select {
+			case l.slots <- struct{}{}:
+			default:
+				metrics.rejections.WithLabelValues(l.host, kindConcurrency).Inc()
+				return nil, fmt.Errorf("%s concurrency: %w", l.host, ErrLimitExceeded)
+			}
+		} else {
+			select {
+			case l.slots <- struct{}{}:
+			case <-r.Context().Done():
+				metrics.rejections.WithLabelValues(l.host, kindConcurrency).Inc()
+				return nil, fmt.Errorf("%s concurrency: %w: %w", l.host, ErrLimitExceeded, r.Context().Err())
+			}
+		}
+		metrics.wait.WithLabelValues(l.host, kindConcurrency).Observe(time.Since(start).Seconds())
+
+		release = func() { <-l.slots }
+	}
+
+	return release, nil
+}
+
+func (b *releaseBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(b.release)
+
+	return err
+}
@@ -0,0 +1,87 @@
+package client
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestMaxConcurrentFailFast(t *testing.T) {
+	var (
+		release = make(chan struct{})
+		srv     = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			<-release
+		}))
+		registry = prometheus.NewRegistry()
+	)
+	defer srv.Close()
+
+	c, err := NewClient("test",
+		WithPrometheusRegisterer(registry),
+		WithMaxConcurrent(AnyHost, 1),
+		WithLimitFailFast(true),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	done := make(chan error)
+	go func() {
+		resp, err := c.Get(srv.URL)
+		if err == nil {
+			err = resp.Body.Close()
+		}
+		done <- err
+	}()
+
+	// wait till the first request holds the slot
+	for testutil.CollectAndCount(registry, "test_http_outgoing_limit_wait_duration_histogram_seconds") == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	if _, err := c.Get(srv.URL); !errors.Is(err, ErrLimitExceeded) {
+		t.Errorf("Get() = %v, want %v", err, ErrLimitExceeded)
+	}
+
+	close(release)
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := c.Get(srv.URL)
+	if err != nil {
+		t.Fatalf("Get() after release = %v", err)
+	}
+	_ = resp.Body.Close()
+}
+
+func TestRateLimitWait(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+
+	c, err := NewClient("test",
+		WithPrometheusRegisterer(prometheus.NewRegistry()),
+		WithRateLimit(srv.Listener.Addr().String(), 20, 0), // burst of 1
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		resp, err := c.Get(srv.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_ = resp.Body.Close()
+	}
+
+	if elapsed := time.Since(start); elapsed < time.Millisecond*90 {
+		t.Errorf("3 requests at 20 rps took %v, want >= 100ms", elapsed)
+	}
+}
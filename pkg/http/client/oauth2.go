@@ -0,0 +1,169 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+
+	pph "github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"github.com/fusionmedialimited/backend-infra-libraries/v3/pkg/primitives"
+)
+
+type (
+	OAuth2Config struct {
+		TokenURL     string
+		ClientID     string
+		ClientSecret string
+		Scopes       []string
+		// Transport sends token requests without signing and propagated headers.
+		Transport http.RoundTripper
+	}
+
+	oauth2Token struct {
+		AccessToken string `json:"access_token"`
+		TokenType   string `json:"token_type"`
+		ExpiresIn   int64  `json:"expires_in"`
+	}
+
+	tokenSource struct {
+		conf  OAuth2Config
+		group singleflight.Group
+
+		mu      sync.Mutex
+		token   string
+		refresh time.Time
+	}
+)
+
+func InstrumentRoundTripperOAuth2(conf OAuth2Config, next http.RoundTripper) pph.RoundTripperFunc {
+	if conf.Transport == nil {
+		conf.Transport = http.DefaultTransport
+	}
+	source := &tokenSource{conf: conf}
+
+	return func(r *http.Request) (*http.Response, error) {
+		token, err := source.get(r.Context())
+		if err != nil {
+			return nil, err
+		}
+
+		r = r.Clone(r.Context())
+		r.Header.Set("Authorization", "Bearer "+token)
+
+		resp, err := next.RoundTrip(r)
+		if err == nil && resp.StatusCode == http.StatusUnauthorized {
+			source.reset(token)
+		}
+
+		return resp, err
+	}
+}
+
+// get shares one fetch, which isn't canceled by any of requests.
+func (s *tokenSource) get(ctx context.Context) (string, error) {
+	const fetchTimeout = time.Second * 10
+
+	s.mu.Lock()
+	if s.token != "" && time.Now().Before(s.refresh) {
+		defer s.mu.Unlock()
+		return s.token, nil
+	}
+	s.mu.Unlock()
+
+	fetched := s.group.DoChan("token", func() (any, error) {
+		ctx, cancel := context.WithTimeout(withoutCancel(ctx), fetchTimeout)
+		defer cancel()
+
+		token, err := s.fetch(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("oauth2 token: %w", err)
+		}
+
+		// refresh at 80% of the lifetime, so requests don't race with expiry
+		const (
+			refreshRatio    = 0.8
+			defaultLifetime = time.Minute * 5
+		)
+		lifetime := time.Duration(token.ExpiresIn) * time.Second
+		if lifetime <= 0 {
+			lifetime = defaultLifetime
+		}
+
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		s.token = token.AccessToken
+		s.refresh = time.Now().Add(time.Duration(float64(lifetime) * refreshRatio))
+
+		return s.token, nil
+	})
+
+	select {
+	case res := <-fetched:
+		if res.Err != nil {
+			return "", res.Err
+		}
+
+		return res.Val.(string), nil //nolint:forcetypeassert // it's set above
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
+
+func (s *tokenSource) reset(token string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.token == token {
+		s.token = ""
+	}
+}
+
+func (s *tokenSource) fetch(ctx context.Context) (token oauth2Token, _ error) {
+	form := url.Values{"grant_type": {"client_credentials"}}
+	if len(s.conf.Scopes) > 0 {
+		form.Set("scope", strings.Join(s.conf.Scopes, " "))
+	}
+
+	r, err := http.NewRequestWithContext(ctx, http.MethodPost, s.conf.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return token, err
+	}
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	r.Header.Set("Accept", MIMEApplicationJSON)
+	r.SetBasicAuth(url.QueryEscape(s.conf.ClientID), url.QueryEscape(s.conf.ClientSecret))
+
+	resp, err := s.conf.Transport.RoundTrip(r)
+	if err != nil {
+		return token, err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, defaultMaxResponseSize))
+	if err != nil {
+		return token, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return token, newHTTPError(resp, data)
+	}
+
+	if err = primitives.UnmarshalJSON(data, &token); err != nil {
+		return token, err
+	}
+
+	if token.AccessToken == "" {
+		return token, errors.New("no access_token in response")
+	}
+
+	return token, nil
+}
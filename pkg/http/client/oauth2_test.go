@@ -0,0 +1,76 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/fusionmedialimited/backend-infra-libraries/v3/pkg/http/signature"
+)
+
+func TestOAuth2ClientCredentials(t *testing.T) {
+	var fetches atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/token" {
+			id, secret, _ := r.BasicAuth()
+			if id != "id" || secret != "secret" || r.FormValue("scope") != "read write" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			if r.Header.Get(signature.HeaderSignature) != "" || r.Header.Get("X-Tenant-Id") != "" {
+				t.Errorf("token request is signed or has propagated headers: %v", r.Header)
+			}
+
+			// default lifetime is used without expires_in
+			fetches.Add(1)
+			_, _ = w.Write([]byte(`{"access_token":"token","token_type":"bearer"}`))
+			return
+		}
+
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	}))
+	defer srv.Close()
+
+	c, err := NewClient("test",
+		WithPrometheusRegisterer(prometheus.NewRegistry()),
+		WithOAuth2ClientCredentials(srv.URL+"/token", "id", "secret", "read", "write"),
+		WithRequestSigner(signature.HMACSigner{KeyID: "svc", Key: []byte("secret")}),
+		WithHeaderPropagation(),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := WithPropagatedHeaders(context.Background(), http.Header{"X-Tenant-Id": {"a"}})
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			req, _ := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL+"/api", nil)
+			resp, err := c.Do(req)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			_ = resp.Body.Close()
+
+			if resp.StatusCode != http.StatusOK {
+				t.Errorf("status = %d, want 200", resp.StatusCode)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if fetches.Load() != 1 {
+		t.Errorf("token is fetched %d times, want 1", fetches.Load())
+	}
+}
@@ -0,0 +1,180 @@
+package client
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/getkin/kin-openapi/routers/legacy"
+	"github.com/prometheus/client_golang/prometheus"
+	pph "github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const (
+	OpenAPIReport OpenAPIMode = iota
+	OpenAPIEnforce
+)
+
+var ErrContractViolation = errors.New("openapi contract violation")
+
+type (
+	OpenAPIMode int
+
+	OpenAPISpec struct {
+		Spec *openapi3.T
+		Mode OpenAPIMode
+	}
+
+	openAPIRouter struct {
+		router    routers.Router
+		basePaths []string
+	}
+)
+
+// InstrumentRoundTripperOpenAPI validates requests and responses by the spec, responses over 10MB aren't validated.
+func InstrumentRoundTripperOpenAPI(
+	spec OpenAPISpec,
+	violations *prometheus.CounterVec,
+	log *zap.Logger,
+	next http.RoundTripper,
+) (pph.RoundTripperFunc, error) {
+	router, err := newOpenAPIRouter(spec.Spec)
+	if err != nil {
+		return nil, err
+	}
+
+	violation := func(r *http.Request, operation, direction string, err error) error {
+		violations.WithLabelValues(operation, direction).Inc()
+		log.Warn("OpenAPI contract violation",
+			zap.String("operation", operation),
+			zap.String("direction", direction),
+			zap.String("method", r.Method),
+			zap.String("url", r.URL.Redacted()),
+			zap.Error(err),
+		)
+
+		if spec.Mode == OpenAPIEnforce {
+			return fmt.Errorf("%s %s: %w: %w", direction, operation, ErrContractViolation, err)
+		}
+
+		return nil
+	}
+
+	return func(r *http.Request) (*http.Response, error) {
+		const directionRequest, directionResponse = "request", "response"
+
+		route, params, err := router.find(r)
+		if err != nil {
+			if err := violation(r, "unknown", directionRequest, err); err != nil {
+				return nil, err
+			}
+
+			return next.RoundTrip(r)
+		}
+
+		operation := route.Operation.OperationID
+		if operation == "" {
+			operation = route.Method + " " + route.Path
+		}
+
+		r = r.Clone(r.Context()) // validation replaces the body
+		input := &openapi3filter.RequestValidationInput{
+			Request:    r,
+			PathParams: params,
+			Route:      route,
+			Options:    &openapi3filter.Options{AuthenticationFunc: openapi3filter.NoopAuthenticationFunc},
+		}
+		if err := openapi3filter.ValidateRequest(r.Context(), input); err != nil {
+			if err := violation(r, operation, directionRequest, err); err != nil {
+				return nil, err
+			}
+		}
+
+		resp, err := next.RoundTrip(r)
+		if err != nil {
+			return nil, err
+		}
+
+		body, err := io.ReadAll(io.LimitReader(resp.Body, defaultMaxResponseSize+1))
+		if err != nil {
+			_ = resp.Body.Close()
+			return nil, fmt.Errorf("read response: %w", err)
+		}
+		if len(body) > defaultMaxResponseSize {
+			// large responses aren't validated, they're passed as is
+			resp.Body = struct {
+				io.Reader
+				io.Closer
+			}{io.MultiReader(bytes.NewReader(body), resp.Body), resp.Body}
+			return resp, nil
+		}
+		_ = resp.Body.Close()
+		resp.Body = io.NopCloser(bytes.NewReader(body))
+
+		err = openapi3filter.ValidateResponse(r.Context(), &openapi3filter.ResponseValidationInput{
+			RequestValidationInput: input,
+			Status:                 resp.StatusCode,
+			Header:                 resp.Header,
+			Body:                   io.NopCloser(bytes.NewReader(body)),
+		})
+		if err != nil {
+			if err := violation(r, operation, directionResponse, err); err != nil {
+				return nil, err
+			}
+		}
+
+		return resp, nil
+	}, nil
+}
+
+func newOpenAPIRouter(spec *openapi3.T) (*openAPIRouter, error) {
+	doc := *spec
+	doc.Servers = nil // servers of the environment may differ from the spec
+
+	router, err := legacy.NewRouter(&doc)
+	if err != nil {
+		return nil, fmt.Errorf("openapi router: %w", err)
+	}
+
+	basePaths := make([]string, 0, len(spec.Servers))
+	for _, server := range spec.Servers {
+		if basePath, err := server.BasePath(); err == nil && basePath != "/" {
+			basePaths = append(basePaths, strings.TrimRight(basePath, "/"))
+		}
+	}
+
+	return &openAPIRouter{router: router, basePaths: basePaths}, nil
+}
+
+func (r *openAPIRouter) find(req *http.Request) (*routers.Route, map[string]string, error) {
+	route, params, err := r.router.FindRoute(req)
+	if err == nil {
+		return route, params, nil
+	}
+
+	for _, basePath := range r.basePaths {
+		if !strings.HasPrefix(req.URL.Path, basePath+"/") {
+			continue
+		}
+
+		var (
+			probe = *req
+			u     = *req.URL
+		)
+		u.Path, u.RawPath = strings.TrimPrefix(req.URL.Path, basePath), ""
+		probe.URL = &u
+		if route, params, err := r.router.FindRoute(&probe); err == nil {
+			return route, params, nil
+		}
+	}
+
+	return nil, nil, err
+}
@@ -0,0 +1,124 @@
+package client
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+const testSpec = `
+openapi: 3.0.0
+info: {title: users, version: "1"}
+servers:
+  - url: https://users.svc/v1
+paths:
+  /users/{id}:
+    get:
+      operationId: getUser
+      parameters:
+        - {name: id, in: path, required: true, schema: {type: integer}}
+      responses:
+        "200":
+          description: user
+          content:
+            application/json:
+              schema:
+                type: object
+                required: [id]
+                properties:
+                  id: {type: integer}
+`
+
+func TestOpenAPI(t *testing.T) {
+	spec, err := openapi3.NewLoader().LoadFromData([]byte(testSpec))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", MIMEApplicationJSON)
+		if r.URL.Path == "/v1/users/2" {
+			_, _ = w.Write([]byte(`{"name":"john"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"id":1}`))
+	}))
+	defer srv.Close()
+
+	for _, tc := range []struct {
+		mode    OpenAPIMode
+		path    string
+		wantErr bool
+		want    string
+	}{
+		{OpenAPIEnforce, "/v1/users/1", false, ""},
+		{OpenAPIEnforce, "/v1/users/john", true, `direction="request",operation="getUser"`},
+		{OpenAPIEnforce, "/v1/users/2", true, `direction="response",operation="getUser"`},
+		{OpenAPIReport, "/v1/users/2", false, `direction="response",operation="getUser"`},
+	} {
+		registry := prometheus.NewRegistry()
+		c, err := NewClient("test", WithPrometheusRegisterer(registry), WithOpenAPISpec(spec, tc.mode))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		resp, err := c.Get(srv.URL + tc.path)
+		if err == nil {
+			_ = resp.Body.Close()
+		}
+
+		if got := errors.Is(err, ErrContractViolation); got != tc.wantErr {
+			t.Errorf("Get(%s) in mode %d = %v, want error %v", tc.path, tc.mode, err, tc.wantErr)
+		}
+
+		if tc.want == "" {
+			continue
+		}
+
+		want := `
+# HELP test_http_outgoing_contract_violations_total A counter for OpenAPI contract violations by requests and responses.
+# TYPE test_http_outgoing_contract_violations_total counter
+test_http_outgoing_contract_violations_total{` + tc.want + `} 1
+`
+		if err := testutil.GatherAndCompare(registry, strings.NewReader(want), "test_http_outgoing_contract_violations_total"); err != nil {
+			t.Errorf("Get(%s): %v", tc.path, err)
+		}
+	}
+}
+
+func TestOpenAPILargeResponse(t *testing.T) {
+	spec, err := openapi3.NewLoader().LoadFromData([]byte(testSpec))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	large := `{"name":"` + strings.Repeat("a", defaultMaxResponseSize) + `"}`
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", MIMEApplicationJSON)
+		_, _ = w.Write([]byte(large))
+	}))
+	defer srv.Close()
+
+	c, err := NewClient("test", WithPrometheusRegisterer(prometheus.NewRegistry()), WithOpenAPISpec(spec, OpenAPIEnforce))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := c.Get(srv.URL + "/v1/users/1")
+	if err != nil {
+		t.Fatalf("Get() = %v, want large response passed without validation", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil || len(body) != len(large) {
+		t.Errorf("body of %d bytes, %v, want %d bytes", len(body), err, len(large))
+	}
+}
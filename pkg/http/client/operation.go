@@ -0,0 +1,83 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	pph "github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const labelOperation = "operation"
+
+type operationKey struct{}
+
+// WithOperation labels metrics and spans of requests, it must be of low cardinality, e.g. `getUser`.
+func WithOperation(ctx context.Context, operation string) context.Context {
+	return context.WithValue(ctx, operationKey{}, operation)
+}
+
+func OperationFromContext(ctx context.Context) string {
+	operation, _ := ctx.Value(operationKey{}).(string)
+	return operation
+}
+
+func InstrumentRoundTripperOperation(templates []string, next http.RoundTripper) pph.RoundTripperFunc {
+	parsed := make([][]string, 0, len(templates))
+	for _, template := range templates {
+		parsed = append(parsed, splitPath(template))
+	}
+
+	return func(r *http.Request) (*http.Response, error) {
+		if OperationFromContext(r.Context()) != "" {
+			return next.RoundTrip(r)
+		}
+
+		path := splitPath(r.URL.Path)
+		for i, template := range parsed {
+			if matchPath(template, path) {
+				r = r.WithContext(WithOperation(r.Context(), templates[i]))
+				break
+			}
+		}
+
+		return next.RoundTrip(r)
+	}
+}
+
+func InstrumentRoundTripperInFlight(gauge *prometheus.GaugeVec, next http.RoundTripper) pph.RoundTripperFunc {
+	return func(r *http.Request) (*http.Response, error) {
+		inflight := gauge.WithLabelValues(OperationFromContext(r.Context()))
+		inflight.Inc()
+		defer inflight.Dec()
+
+		return next.RoundTrip(r)
+	}
+}
+
+func spanName(_ string, r *http.Request) string {
+	if operation := OperationFromContext(r.Context()); operation != "" {
+		return "HTTP " + r.Method + " " + operation
+	}
+
+	return "HTTP " + r.Method
+}
+
+func splitPath(path string) []string {
+	return strings.Split(strings.Trim(path, "/"), "/")
+}
+
+func matchPath(template, path []string) bool {
+	if len(template) != len(path) {
+		return false
+	}
+
+	for i, segment := range template {
+		if segment != path[i] && !(strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}")) {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,69 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestOperation(t *testing.T) {
+	var (
+		srv      = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+		registry = prometheus.NewRegistry()
+	)
+	defer srv.Close()
+
+	c, err := NewClient("test", WithPrometheusRegisterer(registry), WithRouteTemplates("/users/{id}"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, req := range []struct {
+		ctx  context.Context
+		path string
+	}{
+		{context.Background(), "/users/1"},
+		{context.Background(), "/users/2"},
+		{WithOperation(context.Background(), "getOrder"), "/orders/1"},
+		{context.Background(), "/other"},
+	} {
+		r, _ := http.NewRequestWithContext(req.ctx, http.MethodGet, srv.URL+req.path, nil)
+		resp, err := c.Do(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_ = resp.Body.Close()
+	}
+
+	want := `
+# HELP test_http_outgoing_requests_total A counter for outgoing requests from the wrapped client.
+# TYPE test_http_outgoing_requests_total counter
+test_http_outgoing_requests_total{code="200",method="get",operation=""} 1
+test_http_outgoing_requests_total{code="200",method="get",operation="/users/{id}"} 2
+test_http_outgoing_requests_total{code="200",method="get",operation="getOrder"} 1
+`
+	if err := testutil.GatherAndCompare(registry, strings.NewReader(want), "test_http_outgoing_requests_total"); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestMatchPath(t *testing.T) {
+	for _, tc := range []struct {
+		template, path string
+		want           bool
+	}{
+		{"/users/{id}", "/users/1", true},
+		{"/users/{id}", "/users/1/orders", false},
+		{"/users/{id}/orders", "/users/1/orders/", true},
+		{"/users", "/orders", false},
+	} {
+		if got := matchPath(splitPath(tc.template), splitPath(tc.path)); got != tc.want {
+			t.Errorf("matchPath(%q, %q) = %v, want %v", tc.template, tc.path, got, tc.want)
+		}
+	}
+}
@@ -1,10 +1,14 @@
 package client
 
 import (
+	"net/http"
+	"net/url"
 	"time"
 
 	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
 
+	"github.com/getkin/kin-openapi/openapi3"
 	"github.com/prometheus/client_golang/prometheus"
 )
 
@@ -22,6 +26,12 @@ func WithMaxIdleConnections(count int) OptionFunc {
 	}
 }
 
+func WithMaxIdleConnectionsPerHost(count int) OptionFunc {
+	return func(config *Config) {
+		config.MaxIdleConnectionsPerHost = count
+	}
+}
+
 func WithIdleConnTimeout(timeout time.Duration) OptionFunc {
 	return func(config *Config) {
 		config.IdleConnTimeout = timeout
@@ -34,6 +44,54 @@ func WithDisableCompression(isDisabled bool) OptionFunc {
 	}
 }
 
+func WithTransport(transport http.RoundTripper) OptionFunc {
+	return func(config *Config) {
+		config.Transport = transport
+	}
+}
+
+func WithDialTimeout(timeout time.Duration) OptionFunc {
+	return func(config *Config) {
+		config.DialTimeout = timeout
+	}
+}
+
+func WithTLSHandshakeTimeout(timeout time.Duration) OptionFunc {
+	return func(config *Config) {
+		config.TLSHandshakeTimeout = timeout
+	}
+}
+
+func WithResponseHeaderTimeout(timeout time.Duration) OptionFunc {
+	return func(config *Config) {
+		config.ResponseHeaderTimeout = timeout
+	}
+}
+
+func WithMaxConnsPerHost(count int) OptionFunc {
+	return func(config *Config) {
+		config.MaxConnsPerHost = count
+	}
+}
+
+func WithProxy(proxy func(*http.Request) (*url.URL, error)) OptionFunc {
+	return func(config *Config) {
+		config.Proxy = proxy
+	}
+}
+
+func WithTLS(conf TLSConfig) OptionFunc {
+	return func(config *Config) {
+		config.TLS = &conf
+	}
+}
+
+func WithHTTP2(conf HTTP2Config) OptionFunc {
+	return func(config *Config) {
+		config.HTTP2 = conf
+	}
+}
+
 func WithPrometheusRegisterer(registerer prometheus.Registerer) OptionFunc {
 	return func(config *Config) {
 		config.PrometheusRegisterer = registerer
@@ -52,8 +110,134 @@ func WithServiceName(serviceName string) OptionFunc {
 	}
 }
 
+// WithServiceLabel sets service label even if the name is empty, so clients of one registry share label names.
+func WithServiceLabel() OptionFunc {
+	return func(config *Config) {
+		config.ServiceLabel = true
+	}
+}
+
 func WithTraceProvider(provider trace.TracerProvider) OptionFunc {
 	return func(config *Config) {
 		config.OTELTraceProvider = provider
 	}
 }
+
+func WithRetry(policy RetryPolicy) OptionFunc {
+	return func(config *Config) {
+		config.Retry = &policy
+	}
+}
+
+func WithCircuitBreaker(policy BreakerPolicy) OptionFunc {
+	return func(config *Config) {
+		config.Breaker = &policy
+	}
+}
+
+// WithRateLimit limits requests per second to the host or to AnyHost.
+func WithRateLimit(host string, rps float64, burst int) OptionFunc {
+	return func(config *Config) {
+		limit := config.Limits[host]
+		limit.RPS, limit.Burst = rps, burst
+		setLimit(config, host, limit)
+	}
+}
+
+// WithMaxConcurrent limits concurrent requests to the host or to AnyHost.
+func WithMaxConcurrent(host string, n int) OptionFunc {
+	return func(config *Config) {
+		limit := config.Limits[host]
+		limit.MaxConcurrent = n
+		setLimit(config, host, limit)
+	}
+}
+
+func WithLimitFailFast(failFast bool) OptionFunc {
+	return func(config *Config) {
+		config.LimitFailFast = failFast
+	}
+}
+
+func setLimit(config *Config, host string, limit Limit) {
+	if config.Limits == nil {
+		config.Limits = make(map[string]Limit)
+	}
+
+	config.Limits[host] = limit
+}
+
+// WithRouteTemplates labels requests by the first matched template, e.g. `/users/{id}`.
+func WithRouteTemplates(templates ...string) OptionFunc {
+	return func(config *Config) {
+		config.RouteTemplates = append(config.RouteTemplates, templates...)
+	}
+}
+
+func WithOpenAPISpec(spec *openapi3.T, mode OpenAPIMode) OptionFunc {
+	return func(config *Config) {
+		config.OpenAPI = &OpenAPISpec{Spec: spec, Mode: mode}
+	}
+}
+
+func WithLogger(log *zap.Logger) OptionFunc {
+	return func(config *Config) {
+		config.Logger = log
+	}
+}
+
+func WithOAuth2ClientCredentials(tokenURL, clientID, clientSecret string, scopes ...string) OptionFunc {
+	return func(config *Config) {
+		config.OAuth2 = &OAuth2Config{TokenURL: tokenURL, ClientID: clientID, ClientSecret: clientSecret, Scopes: scopes}
+	}
+}
+
+func WithRequestSigner(signer RequestSigner) OptionFunc {
+	return func(config *Config) {
+		config.Signer = signer
+	}
+}
+
+// WithCache serves GET requests from the store, entries are keyed by the headers, IdentityHeaders by default.
+func WithCache(store CacheStore, headers ...string) OptionFunc {
+	if len(headers) == 0 {
+		headers = IdentityHeaders
+	}
+
+	return func(config *Config) {
+		config.Cache = store
+		config.CacheHeaders = headers
+	}
+}
+
+// WithCoalescing shares one response between identical concurrent GET and HEAD requests.
+func WithCoalescing(headers ...string) OptionFunc {
+	return func(config *Config) {
+		config.Coalesce = true
+		config.CoalesceHeaders = headers
+	}
+}
+
+func WithHedging(policy HedgePolicy) OptionFunc {
+	return func(config *Config) {
+		config.Hedge = &policy
+	}
+}
+
+// WithHeaderPropagation copies the headers put into the context by WithPropagatedHeaders.
+func WithHeaderPropagation(headers ...string) OptionFunc {
+	if len(headers) == 0 {
+		headers = DefaultPropagatedHeaders
+	}
+
+	return func(config *Config) {
+		config.PropagateHeaders = headers
+	}
+}
+
+// WithFaultInjection injects faults into requests, it's for chaos testing only.
+func WithFaultInjection(injector *FaultInjector) OptionFunc {
+	return func(config *Config) {
+		config.Faults = injector
+	}
+}
@@ -0,0 +1,57 @@
+package client
+
+import (
+	"context"
+	"net/http"
+
+	pph "github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"github.com/fusionmedialimited/backend-infra-libraries/v3/pkg/http/propagation"
+)
+
+var DefaultPropagatedHeaders = []string{"X-Request-Id", "X-Tenant-Id", "X-User-Id", "Accept-Language"}
+
+// WithPropagatedHeaders puts headers of the incoming request into the context.
+func WithPropagatedHeaders(ctx context.Context, header http.Header) context.Context {
+	return propagation.WithHeaders(ctx, header)
+}
+
+func PropagatedHeadersFromContext(ctx context.Context) http.Header {
+	return propagation.HeadersFromContext(ctx)
+}
+
+// InstrumentRoundTripperPropagation copies allowed headers of the context unless they're set.
+func InstrumentRoundTripperPropagation(allow []string, next http.RoundTripper) pph.RoundTripperFunc {
+	allow = canonicalHeaders(allow)
+
+	return func(r *http.Request) (*http.Response, error) {
+		propagated := PropagatedHeadersFromContext(r.Context())
+		if len(propagated) == 0 {
+			return next.RoundTrip(r)
+		}
+
+		cloned := false
+		for _, name := range allow {
+			values := propagated.Values(name)
+			if len(values) == 0 || r.Header.Get(name) != "" {
+				continue
+			}
+
+			if !cloned {
+				r, cloned = r.Clone(r.Context()), true
+			}
+			r.Header[name] = append([]string(nil), values...)
+		}
+
+		return next.RoundTrip(r)
+	}
+}
+
+func canonicalHeaders(names []string) []string {
+	canonical := make([]string, len(names))
+	for i, name := range names {
+		canonical[i] = http.CanonicalHeaderKey(name)
+	}
+
+	return canonical
+}
@@ -0,0 +1,64 @@
+package client
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func TestPropagationTenants(t *testing.T) {
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		w.Header().Set("Cache-Control", "max-age=60")
+		_, _ = w.Write([]byte(r.Header.Get("X-Tenant-Id")))
+	}))
+	defer srv.Close()
+
+	c, err := NewClient("test",
+		WithPrometheusRegisterer(prometheus.NewRegistry()),
+		WithHeaderPropagation(),
+		WithCache(NewLRUStore(10)),
+		WithCoalescing(),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	get := func(tenant string) {
+		ctx := WithPropagatedHeaders(context.Background(), http.Header{"X-Tenant-Id": {tenant}})
+		req, _ := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL, http.NoBody)
+		resp, err := c.Do(req)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		defer resp.Body.Close()
+
+		if body, _ := io.ReadAll(resp.Body); string(body) != tenant {
+			t.Errorf("tenant %s got response of %q", tenant, body)
+		}
+	}
+
+	// concurrent requests are coalesced, then later ones are served from the cache
+	var wg sync.WaitGroup
+	for _, tenant := range []string{"a", "b", "a", "b"} {
+		wg.Add(1)
+		go func(tenant string) {
+			defer wg.Done()
+			get(tenant)
+		}(tenant)
+	}
+	time.Sleep(time.Millisecond * 50)
+	close(release)
+	wg.Wait()
+
+	get("b")
+	get("a")
+}
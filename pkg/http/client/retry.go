@@ -0,0 +1,205 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	pph "github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const HeaderIdempotencyKey = "Idempotency-Key"
+
+// RetryPolicy retries idempotent requests and requests with HeaderIdempotencyKey,
+// zero fields are taken from DefaultRetryPolicy.
+type RetryPolicy struct {
+	MaxAttempts   int
+	MinBackoff    time.Duration
+	MaxBackoff    time.Duration
+	MaxRetryAfter time.Duration
+	Retryable     func(resp *http.Response, err error) bool
+}
+
+func DefaultRetryPolicy() RetryPolicy {
+	const (
+		defaultMaxAttempts   = 3
+		defaultMinBackoff    = time.Millisecond * 100
+		defaultMaxBackoff    = time.Second * 2
+		defaultMaxRetryAfter = time.Second * 10
+	)
+
+	return RetryPolicy{
+		MaxAttempts:   defaultMaxAttempts,
+		MinBackoff:    defaultMinBackoff,
+		MaxBackoff:    defaultMaxBackoff,
+		MaxRetryAfter: defaultMaxRetryAfter,
+		Retryable:     DefaultRetryable,
+	}
+}
+
+func (p RetryPolicy) withDefaults() RetryPolicy {
+	def := DefaultRetryPolicy()
+	if p.MaxAttempts <= 0 {
+		p.MaxAttempts = def.MaxAttempts
+	}
+	if p.MinBackoff <= 0 {
+		p.MinBackoff = def.MinBackoff
+	}
+	if p.MaxBackoff <= 0 {
+		p.MaxBackoff = def.MaxBackoff
+	}
+	if p.MaxBackoff < p.MinBackoff {
+		p.MaxBackoff = p.MinBackoff
+	}
+	if p.MaxRetryAfter <= 0 {
+		p.MaxRetryAfter = def.MaxRetryAfter
+	}
+	if p.Retryable == nil {
+		p.Retryable = def.Retryable
+	}
+
+	return p
+}
+
+// DefaultRetryable retries transport errors and 429, 502, 503 and 504 responses.
+func DefaultRetryable(resp *http.Response, err error) bool {
+	if err != nil {
+		return !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded) &&
+			!errors.Is(err, ErrCircuitOpen)
+	}
+
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	default:
+		return false
+	}
+}
+
+func InstrumentRoundTripperRetry(policy RetryPolicy, counter *prometheus.CounterVec, next http.RoundTripper) pph.RoundTripperFunc {
+	policy = policy.withDefaults()
+
+	return func(r *http.Request) (*http.Response, error) {
+		if !isRetriable(r) {
+			return next.RoundTrip(r)
+		}
+
+		for attempt := 1; ; attempt++ {
+			resp, err := next.RoundTrip(r)
+			if attempt >= policy.MaxAttempts || !policy.Retryable(resp, err) {
+				return resp, err
+			}
+
+			wait := policy.backoff(attempt)
+			if resp != nil {
+				if after, ok := retryAfter(resp); ok {
+					wait = after
+				}
+				if wait > policy.MaxRetryAfter {
+					wait = policy.MaxRetryAfter
+				}
+			}
+
+			if deadline, ok := r.Context().Deadline(); ok && time.Until(deadline) < wait {
+				return resp, err
+			}
+
+			body, bodyErr := rewind(r)
+			if bodyErr != nil {
+				return resp, err
+			}
+
+			if resp != nil {
+				drain(resp)
+			}
+
+			if err := sleep(r.Context(), wait); err != nil {
+				return nil, err
+			}
+
+			counter.WithLabelValues(r.Method).Inc()
+			r = r.Clone(r.Context())
+			r.Body = body
+		}
+	}
+}
+
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	backoff := p.MaxBackoff
+	// compared before shifting, so large backoffs don't overflow
+	if shift := attempt - 1; p.MinBackoff <= p.MaxBackoff>>shift {
+		backoff = p.MinBackoff << shift
+	}
+
+	if backoff <= 1 {
+		return backoff
+	}
+
+	return backoff/2 + time.Duration(rand.Int63n(int64(backoff/2))) //nolint:gosec // jitter doesn't need crypto
+}
+
+func isRetriable(r *http.Request) bool {
+	if r.Body != nil && r.Body != http.NoBody && r.GetBody == nil {
+		return false
+	}
+
+	switch r.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodPut, http.MethodDelete:
+		return true
+	default:
+		return r.Header.Get(HeaderIdempotencyKey) != ""
+	}
+}
+
+func rewind(r *http.Request) (io.ReadCloser, error) {
+	if r.GetBody == nil {
+		return r.Body, nil
+	}
+
+	return r.GetBody()
+}
+
+func retryAfter(resp *http.Response) (time.Duration, bool) {
+	header := resp.Header.Get("Retry-After")
+	if header == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(header); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	if date, err := http.ParseTime(header); err == nil {
+		if wait := time.Until(date); wait > 0 {
+			return wait, true
+		}
+
+		return 0, true
+	}
+
+	return 0, false
+}
+
+func drain(resp *http.Response) {
+	const maxDrain = 4 << 10
+
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, maxDrain))
+	_ = resp.Body.Close()
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
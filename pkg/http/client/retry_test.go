@@ -0,0 +1,160 @@
+package client
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func newFlakyServer(t *testing.T, failures int32, header http.Header) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+
+	var attempts atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if body, _ := io.ReadAll(r.Body); r.Method == http.MethodPost && string(body) != "payload" {
+			t.Errorf("attempt %d body = %q", attempts.Load(), body)
+		}
+
+		if attempts.Add(1) <= failures {
+			for key, vals := range header {
+				w.Header()[key] = vals
+			}
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(srv.Close)
+
+	return srv, &attempts
+}
+
+func TestRetry(t *testing.T) {
+	var (
+		srv, attempts = newFlakyServer(t, 2, nil)
+		registry      = prometheus.NewRegistry()
+		policy        = RetryPolicy{MaxAttempts: 3, MinBackoff: time.Millisecond, MaxBackoff: time.Millisecond * 10}
+	)
+	c, err := NewClient("test", WithPrometheusRegisterer(registry), WithRetry(policy))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req, _ := http.NewRequest(http.MethodPost, srv.URL, strings.NewReader("payload"))
+	req.Header.Set(HeaderIdempotencyKey, "key")
+	resp, err := c.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK || attempts.Load() != 3 {
+		t.Errorf("status = %d after %d attempts, want 200 after 3", resp.StatusCode, attempts.Load())
+	}
+
+	if got := testutil.CollectAndCount(registry, "test_http_outgoing_retries_total"); got != 1 {
+		t.Errorf("retries_total series = %d, want 1", got)
+	}
+}
+
+func TestRetryNonIdempotent(t *testing.T) {
+	srv, attempts := newFlakyServer(t, 1, nil)
+	c, err := NewClient("test", WithPrometheusRegisterer(prometheus.NewRegistry()), WithRetry(DefaultRetryPolicy()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := c.Post(srv.URL, "text/plain", strings.NewReader("payload"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+
+	if resp.StatusCode != http.StatusServiceUnavailable || attempts.Load() != 1 {
+		t.Errorf("status = %d after %d attempts, want 503 after 1", resp.StatusCode, attempts.Load())
+	}
+}
+
+func TestRetryAfterExceedsTimeout(t *testing.T) {
+	srv, attempts := newFlakyServer(t, 1, http.Header{"Retry-After": {"10"}})
+	c, err := NewClient("test",
+		WithPrometheusRegisterer(prometheus.NewRegistry()),
+		WithRetry(DefaultRetryPolicy()),
+		WithTimeout(time.Second),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := c.Get(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+
+	if resp.StatusCode != http.StatusServiceUnavailable || attempts.Load() != 1 {
+		t.Errorf("status = %d after %d attempts, want 503 after 1", resp.StatusCode, attempts.Load())
+	}
+}
+
+func TestRetryZeroPolicy(t *testing.T) {
+	srv, attempts := newFlakyServer(t, 1, nil)
+	c, err := NewClient("test", WithPrometheusRegisterer(prometheus.NewRegistry()), WithRetry(RetryPolicy{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := c.Get(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK || attempts.Load() != 2 {
+		t.Errorf("status = %d after %d attempts, want 200 after 2", resp.StatusCode, attempts.Load())
+	}
+
+	policy := RetryPolicy{MinBackoff: time.Second * 5}.withDefaults()
+	if backoff := policy.backoff(1); backoff < time.Second*2 {
+		t.Errorf("backoff(1) = %s, want >= MinBackoff/2", backoff)
+	}
+}
+
+func TestRetryBackoffOverflow(t *testing.T) {
+	policy := RetryPolicy{MinBackoff: time.Second * 5, MaxBackoff: time.Minute}.withDefaults()
+	for attempt := 1; attempt <= 100; attempt++ {
+		if backoff := policy.backoff(attempt); backoff < policy.MinBackoff/2 || backoff > policy.MaxBackoff {
+			t.Fatalf("backoff(%d) = %s, want in %s..%s", attempt, backoff, policy.MinBackoff/2, policy.MaxBackoff)
+		}
+	}
+}
+
+func TestRetryAfterCapped(t *testing.T) {
+	srv, attempts := newFlakyServer(t, 1, http.Header{"Retry-After": {"3600"}})
+	c, err := NewClient("test",
+		WithPrometheusRegisterer(prometheus.NewRegistry()),
+		WithRetry(RetryPolicy{MaxRetryAfter: time.Millisecond * 10}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Now()
+	resp, err := c.Get(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK || attempts.Load() != 2 || time.Since(start) > time.Second {
+		t.Errorf("status = %d after %d attempts in %s, want 200 after 2", resp.StatusCode, attempts.Load(), time.Since(start))
+	}
+}
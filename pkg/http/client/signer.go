@@ -0,0 +1,48 @@
+package client
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	pph "github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"github.com/fusionmedialimited/backend-infra-libraries/v3/pkg/http/signature"
+)
+
+var ErrSignedBodyTooLarge = errors.New("request body is too large to sign")
+
+// RequestSigner signs the request, e.g. signature.HMACSigner, body is already read and may be empty.
+type RequestSigner interface {
+	Sign(r *http.Request, body []byte) error
+}
+
+func InstrumentRoundTripperSigner(signer RequestSigner, next http.RoundTripper) pph.RoundTripperFunc {
+	return func(r *http.Request) (*http.Response, error) {
+		var body []byte
+		if r.Body != nil && r.Body != http.NoBody {
+			data, err := io.ReadAll(io.LimitReader(r.Body, signature.MaxBody+1))
+			_ = r.Body.Close()
+			if err != nil {
+				return nil, fmt.Errorf("read request: %w", err)
+			}
+			if len(data) > signature.MaxBody {
+				return nil, fmt.Errorf("%w: over %d bytes", ErrSignedBodyTooLarge, signature.MaxBody)
+			}
+			body = data
+		}
+
+		r = r.Clone(r.Context())
+		if body != nil {
+			r.Body = io.NopCloser(bytes.NewReader(body))
+		}
+
+		if err := signer.Sign(r, body); err != nil {
+			return nil, fmt.Errorf("sign request: %w", err)
+		}
+
+		return next.RoundTrip(r)
+	}
+}
@@ -0,0 +1,31 @@
+package client
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/fusionmedialimited/backend-infra-libraries/v3/pkg/http/signature"
+)
+
+func TestSignerBodyLimit(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	defer srv.Close()
+
+	c, err := NewClient("test",
+		WithPrometheusRegisterer(prometheus.NewRegistry()),
+		WithRequestSigner(signature.HMACSigner{KeyID: "svc", Key: []byte("secret")}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = c.Post(srv.URL, "application/octet-stream", bytes.NewReader(make([]byte, signature.MaxBody+1)))
+	if !errors.Is(err, ErrSignedBodyTooLarge) {
+		t.Errorf("Post() = %v, want %v", err, ErrSignedBodyTooLarge)
+	}
+}
@@ -0,0 +1,218 @@
+package client
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"sync"
+	"time"
+)
+
+var ErrNoCACerts = errors.New("no CA certificates")
+
+type (
+	// TLSConfig configures client certificate and CA by PEM files, rotated ones are used by new connections.
+	TLSConfig struct {
+		CertFile   string
+		KeyFile    string
+		CAFile     string
+		ServerName string
+		MinVersion uint16
+		Refresh    time.Duration
+	}
+
+	tlsFiles struct {
+		conf TLSConfig
+
+		mu       sync.Mutex
+		checked  time.Time
+		modTimes [3]time.Time
+		cert     *tls.Certificate
+		pool     *x509.CertPool
+	}
+)
+
+func (c TLSConfig) build() (*tls.Config, *tlsFiles, error) {
+	const defaultRefresh = time.Minute
+	if c.Refresh == 0 {
+		c.Refresh = defaultRefresh
+	}
+
+	files := &tlsFiles{conf: c}
+	if err := files.load(); err != nil {
+		return nil, nil, err
+	}
+
+	tlsConfig := &tls.Config{ //nolint:gosec // MinVersion is configurable, Go default is used otherwise
+		ServerName: c.ServerName,
+		MinVersion: c.MinVersion,
+	}
+	if c.CertFile != "" {
+		tlsConfig.GetClientCertificate = func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			cert, _ := files.current()
+			return cert, nil
+		}
+	}
+
+	if c.CAFile != "" {
+		// roots can't be changed in tls.Config, so verification is done by the current pool
+		tlsConfig.InsecureSkipVerify = true
+		tlsConfig.VerifyConnection = files.verify(c.ServerName)
+	}
+
+	return tlsConfig, files, nil
+}
+
+func (f *tlsFiles) verify(serverName string) func(tls.ConnectionState) error {
+	return func(state tls.ConnectionState) error {
+		_, pool := f.current()
+		return verifyConnection(state, serverName, pool)
+	}
+}
+
+// dialTLS verifies servers by the dialed host, since SNI isn't sent for IP hosts.
+func (f *tlsFiles) dialTLS(
+	config *tls.Config,
+	timeout time.Duration,
+	dial func(ctx context.Context, network, addr string) (net.Conn, error),
+) func(ctx context.Context, network, addr string) (net.Conn, error) {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		host, _, err := net.SplitHostPort(addr)
+		if err != nil {
+			return nil, err
+		}
+
+		conf := config.Clone()
+		if conf.ServerName == "" {
+			conf.ServerName = host
+		}
+		conf.VerifyConnection = f.verify(conf.ServerName)
+
+		conn, err := dial(ctx, network, addr)
+		if err != nil {
+			return nil, err
+		}
+
+		if timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, timeout)
+			defer cancel()
+		}
+
+		tlsConn := tls.Client(conn, conf)
+		if err = tlsConn.HandshakeContext(ctx); err != nil {
+			_ = conn.Close()
+			return nil, err
+		}
+
+		return tlsConn, nil
+	}
+}
+
+func (f *tlsFiles) current() (*tls.Certificate, *x509.CertPool) {
+	f.mu.Lock()
+	if time.Since(f.checked) < f.conf.Refresh {
+		defer f.mu.Unlock()
+		return f.cert, f.pool
+	}
+	f.mu.Unlock()
+
+	_ = f.load()
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.cert, f.pool
+}
+
+func (f *tlsFiles) load() error {
+	modTimes, err := f.stat()
+	if err != nil {
+		return err
+	}
+
+	f.mu.Lock()
+	unchanged := modTimes == f.modTimes
+	f.checked = time.Now()
+	f.mu.Unlock()
+
+	if unchanged {
+		return nil
+	}
+
+	var (
+		cert *tls.Certificate
+		pool *x509.CertPool
+	)
+	if f.conf.CertFile != "" {
+		pair, err := tls.LoadX509KeyPair(f.conf.CertFile, f.conf.KeyFile)
+		if err != nil {
+			return fmt.Errorf("load client certificate: %w", err)
+		}
+		cert = &pair
+	}
+
+	if f.conf.CAFile != "" {
+		data, err := os.ReadFile(f.conf.CAFile)
+		if err != nil {
+			return fmt.Errorf("read CA: %w", err)
+		}
+
+		pool = x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(data) {
+			return fmt.Errorf("%s: %w", f.conf.CAFile, ErrNoCACerts)
+		}
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.cert, f.pool, f.modTimes = cert, pool, modTimes
+	return nil
+}
+
+func (f *tlsFiles) stat() (modTimes [3]time.Time, _ error) {
+	for i, path := range []string{f.conf.CertFile, f.conf.KeyFile, f.conf.CAFile} {
+		if path == "" {
+			continue
+		}
+
+		info, err := os.Stat(path)
+		if err != nil {
+			return modTimes, fmt.Errorf("stat tls file: %w", err)
+		}
+		modTimes[i] = info.ModTime()
+	}
+
+	return modTimes, nil
+}
+
+func verifyConnection(state tls.ConnectionState, serverName string, roots *x509.CertPool) error {
+	if len(state.PeerCertificates) == 0 {
+		return errors.New("no server certificate")
+	}
+
+	if serverName == "" {
+		serverName = state.ServerName
+	}
+	if serverName == "" {
+		return errors.New("no server name to verify")
+	}
+
+	intermediates := x509.NewCertPool()
+	for _, cert := range state.PeerCertificates[1:] {
+		intermediates.AddCert(cert)
+	}
+
+	_, err := state.PeerCertificates[0].Verify(x509.VerifyOptions{
+		DNSName:       serverName,
+		Roots:         roots,
+		Intermediates: intermediates,
+	})
+
+	return err
+}
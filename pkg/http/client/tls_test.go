@@ -0,0 +1,156 @@
+package client
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func writeClientCert(t *testing.T, dir string, serial int64) (certFile, keyFile string) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: "client"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	certFile, keyFile = filepath.Join(dir, "client.crt"), filepath.Join(dir, "client.key")
+	for path, block := range map[string]*pem.Block{
+		certFile: {Type: "CERTIFICATE", Bytes: der},
+		keyFile:  {Type: "EC PRIVATE KEY", Bytes: keyDER},
+	} {
+		if err := os.WriteFile(path, pem.EncodeToMemory(block), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return certFile, keyFile
+}
+
+func TestMutualTLSRotation(t *testing.T) {
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(r.TLS.PeerCertificates[0].SerialNumber.String()))
+	}))
+	srv.TLS = &tls.Config{ClientAuth: tls.RequireAnyClientCert, MinVersion: tls.VersionTLS12}
+	srv.StartTLS()
+	defer srv.Close()
+
+	dir := t.TempDir()
+	caFile := filepath.Join(dir, "ca.crt")
+	err := os.WriteFile(caFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw}), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	certFile, keyFile := writeClientCert(t, dir, 1)
+	registry := prometheus.NewRegistry()
+	c, err := NewClient("test",
+		WithPrometheusRegisterer(registry),
+		WithTLS(TLSConfig{CertFile: certFile, KeyFile: keyFile, CAFile: caFile, ServerName: "127.0.0.1", Refresh: time.Nanosecond}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	get := func() string {
+		req, _ := http.NewRequest(http.MethodGet, srv.URL, nil)
+		req.Close = true // new connection uses the current certificate
+		resp, err := c.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+
+		buf := make([]byte, 16)
+		n, _ := resp.Body.Read(buf)
+		return string(buf[:n])
+	}
+
+	if serial := get(); serial != "1" {
+		t.Errorf("client certificate serial = %s, want 1", serial)
+	}
+
+	// file modification time may have coarse resolution
+	time.Sleep(time.Millisecond * 10)
+	writeClientCert(t, dir, 2)
+
+	if serial := get(); serial != "2" {
+		t.Errorf("client certificate serial after rotation = %s, want 2", serial)
+	}
+
+	want := `
+# HELP test_http_outgoing_connections_total A counter for connections got by outgoing requests, labeled by reuse of idle ones.
+# TYPE test_http_outgoing_connections_total counter
+test_http_outgoing_connections_total{reused="false"} 2
+`
+	if err := testutil.GatherAndCompare(registry, strings.NewReader(want), "test_http_outgoing_connections_total"); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestTLSServerName(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	defer srv.Close()
+
+	caFile := filepath.Join(t.TempDir(), "ca.crt")
+	err := os.WriteFile(caFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw}), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the certificate of httptest is issued for example.com and 127.0.0.1
+	for _, tc := range []struct {
+		serverName string
+		wantErr    bool
+	}{
+		{"", false}, // the dialed IP is verified
+		{"127.0.0.1", false},
+		{"example.com", false},
+		{"other.test", true},
+	} {
+		c, err := NewClient("test", WithPrometheusRegisterer(prometheus.NewRegistry()), WithTLS(TLSConfig{CAFile: caFile, ServerName: tc.serverName}))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		resp, err := c.Get(srv.URL)
+		if err == nil {
+			_ = resp.Body.Close()
+		}
+		if (err != nil) != tc.wantErr {
+			t.Errorf("Get() with server name %q = %v, want error %v", tc.serverName, err, tc.wantErr)
+		}
+	}
+}
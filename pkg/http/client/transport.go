@@ -0,0 +1,117 @@
+package client
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptrace"
+	"net/url"
+	"strconv"
+	"time"
+
+	"golang.org/x/net/http2"
+
+	"github.com/prometheus/client_golang/prometheus"
+	pph "github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+type (
+	TransportConfig struct {
+		DialTimeout           time.Duration
+		KeepAlive             time.Duration
+		TLSHandshakeTimeout   time.Duration
+		ResponseHeaderTimeout time.Duration
+		ExpectContinueTimeout time.Duration
+		MaxConnsPerHost       int
+		Proxy                 func(*http.Request) (*url.URL, error)
+		TLS                   *TLSConfig
+		HTTP2                 HTTP2Config
+	}
+
+	HTTP2Config struct {
+		Disable         bool
+		ReadIdleTimeout time.Duration
+		PingTimeout     time.Duration
+	}
+)
+
+func defaultTransportConfig() TransportConfig {
+	const (
+		defaultDialTimeout           = time.Second * 30
+		defaultKeepAlive             = time.Second * 30
+		defaultTLSHandshakeTimeout   = time.Second * 10
+		defaultExpectContinueTimeout = time.Second
+	)
+
+	return TransportConfig{
+		DialTimeout:           defaultDialTimeout,
+		KeepAlive:             defaultKeepAlive,
+		TLSHandshakeTimeout:   defaultTLSHandshakeTimeout,
+		ExpectContinueTimeout: defaultExpectContinueTimeout,
+		Proxy:                 http.ProxyFromEnvironment,
+	}
+}
+
+func newTransport(config Config) (*http.Transport, error) {
+	conf := config.TransportConfig
+	dialer := &net.Dialer{Timeout: conf.DialTimeout, KeepAlive: conf.KeepAlive}
+	transport := &http.Transport{
+		Proxy:                 conf.Proxy,
+		DialContext:           dialer.DialContext,
+		MaxIdleConns:          config.MaxIdleConnections,
+		MaxIdleConnsPerHost:   config.MaxIdleConnectionsPerHost,
+		MaxConnsPerHost:       conf.MaxConnsPerHost,
+		IdleConnTimeout:       config.IdleConnTimeout,
+		TLSHandshakeTimeout:   conf.TLSHandshakeTimeout,
+		ResponseHeaderTimeout: conf.ResponseHeaderTimeout,
+		ExpectContinueTimeout: conf.ExpectContinueTimeout,
+		DisableCompression:    config.DisableCompression,
+		ForceAttemptHTTP2:     !conf.HTTP2.Disable,
+	}
+
+	if conf.TLS != nil {
+		tlsConfig, files, err := conf.TLS.build()
+		if err != nil {
+			return nil, err
+		}
+		transport.TLSClientConfig = tlsConfig
+		if conf.TLS.CAFile != "" {
+			transport.DialTLSContext = files.dialTLS(tlsConfig, conf.TLSHandshakeTimeout, dialer.DialContext)
+		}
+	}
+
+	if conf.HTTP2.Disable || conf.HTTP2.ReadIdleTimeout == 0 {
+		return transport, nil
+	}
+
+	h2, err := http2.ConfigureTransports(transport)
+	if err != nil {
+		return nil, err
+	}
+	h2.ReadIdleTimeout, h2.PingTimeout = conf.HTTP2.ReadIdleTimeout, conf.HTTP2.PingTimeout
+
+	return transport, nil
+}
+
+func InstrumentRoundTripperConnections(counter *prometheus.CounterVec, next http.RoundTripper) pph.RoundTripperFunc {
+	return func(r *http.Request) (*http.Response, error) {
+		trace := &httptrace.ClientTrace{
+			GotConn: func(info httptrace.GotConnInfo) {
+				counter.WithLabelValues(strconv.FormatBool(info.Reused)).Inc()
+			},
+		}
+
+		return next.RoundTrip(r.WithContext(httptrace.WithClientTrace(r.Context(), trace)))
+	}
+}
+
+type detachedContext struct{ context.Context }
+
+func (detachedContext) Deadline() (time.Time, bool) { return time.Time{}, false }
+func (detachedContext) Done() <-chan struct{}       { return nil }
+func (detachedContext) Err() error                  { return nil }
+
+// withoutCancel works as context.WithoutCancel of Go 1.21.
+func withoutCancel(ctx context.Context) context.Context {
+	return detachedContext{ctx}
+}
@@ -0,0 +1,37 @@
+package problem
+
+import (
+	"net/http"
+)
+
+const MIMEApplicationProblem = "application/problem+json"
+
+type (
+	// Problem is RFC 7807 problem details, handlers may return it as an error.
+	Problem struct {
+		Type     string       `json:"type,omitempty"`
+		Title    string       `json:"title"`
+		Status   int          `json:"status"`
+		Detail   string       `json:"detail,omitempty"`
+		Instance string       `json:"instance,omitempty"`
+		TraceID  string       `json:"trace_id,omitempty"`
+		Errors   []FieldError `json:"errors,omitempty"`
+	}
+
+	FieldError struct {
+		Field   string `json:"field"`
+		Message string `json:"message"`
+	}
+)
+
+func New(status int, detail string) *Problem {
+	return &Problem{Title: http.StatusText(status), Status: status, Detail: detail}
+}
+
+func (p *Problem) Error() string {
+	if p.Detail == "" {
+		return p.Title
+	}
+
+	return p.Title + ": " + p.Detail
+}
@@ -0,0 +1,18 @@
+package propagation
+
+import (
+	"context"
+	"net/http"
+)
+
+type headersKey struct{}
+
+// WithHeaders puts headers of the incoming request into the context.
+func WithHeaders(ctx context.Context, header http.Header) context.Context {
+	return context.WithValue(ctx, headersKey{}, header)
+}
+
+func HeadersFromContext(ctx context.Context) http.Header {
+	header, _ := ctx.Value(headersKey{}).(http.Header)
+	return header
+}
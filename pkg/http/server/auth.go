@@ -0,0 +1,53 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/fusionmedialimited/backend-infra-libraries/v3/pkg/http/server/validator"
+)
+
+// NewAuthenticators enforces security schemes of the spec: http bearer, oauth2 and openIdConnect
+// by JWT if JWKS is set, apiKey if keys are set and mutualTLS if client certificate names are set.
+// It's empty if nothing is configured, then requirements aren't checked.
+func NewAuthenticators(conf Config) (validator.Authenticators, error) {
+	const jwksTimeout = time.Second * 10
+
+	auth := validator.Authenticators{}
+
+	var keys *validator.JWKS
+	switch {
+	case conf.JWKSFile != "":
+		keys = validator.NewJWKSFromFile(conf.JWKSFile, conf.JWKSRefresh)
+	case conf.JWKSURL != "":
+		keys = validator.NewJWKSFromURL(conf.JWKSURL, &http.Client{Timeout: jwksTimeout}, conf.JWKSRefresh)
+	}
+	if keys != nil {
+		var opts []validator.JWTOptionFunc
+		if conf.JWTOptionalExp {
+			opts = append(opts, validator.WithOptionalExpiration())
+		}
+		jwt := validator.JWTAuthenticator(keys, conf.JWTAudience, conf.JWTIssuer, opts...)
+		auth["bearer"], auth["oauth2"], auth["openIdConnect"] = jwt, jwt, jwt
+	}
+
+	if conf.APIKeys != "" {
+		apiKeys := make(map[string]string)
+		for _, pair := range strings.Split(conf.APIKeys, ",") {
+			subject, key, ok := strings.Cut(strings.TrimSpace(pair), ":")
+			if !ok || subject == "" || key == "" {
+				return nil, errors.New("API keys must be subject:key pairs")
+			}
+			apiKeys[key] = subject
+		}
+		auth["apiKey"] = validator.APIKeyAuthenticator(apiKeys)
+	}
+
+	if len(conf.ClientCertNames) > 0 {
+		auth["mutualTLS"] = validator.ClientCertAuthenticator(conf.ClientCertNames...)
+	}
+
+	return auth, nil
+}
@@ -0,0 +1,34 @@
+package server
+
+import (
+	"testing"
+)
+
+func TestNewAuthenticators(t *testing.T) {
+	for _, test := range []struct {
+		name string
+		conf Config
+		want []string
+	}{
+		{"nothing", Config{}, nil},
+		{"JWKS", Config{JWKSFile: "jwks.json"}, []string{"bearer", "oauth2", "openIdConnect"}},
+		{"API keys", Config{APIKeys: "billing:secret"}, []string{"apiKey"}},
+		{"client certificates", Config{ClientCertNames: []string{"billing"}}, []string{"mutualTLS"}},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			auth, err := NewAuthenticators(test.conf)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(auth) != len(test.want) {
+				t.Errorf("authenticators = %d, want %v", len(auth), test.want)
+			}
+			for _, key := range test.want {
+				if _, ok := auth[key]; !ok {
+					t.Errorf("no %s authenticator", key)
+				}
+			}
+		})
+	}
+}
@@ -2,23 +2,80 @@ package server
 
 import (
 	"context"
+	"fmt"
 	"net"
 	"net/http"
+	"time"
 
+	"go.opentelemetry.io/contrib/instrumentation/github.com/labstack/echo/otelecho"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 
 	"github.com/getkin/kin-openapi/openapi3"
-	"github.com/labstack/echo-contrib/prometheus"
+	"github.com/labstack/echo-contrib/echoprometheus"
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/swaggo/echo-swagger"
 
 	"github.com/fusionmedialimited/backend-infra-libraries/v3/pkg/config"
 	"github.com/fusionmedialimited/backend-infra-libraries/v3/pkg/di"
+	"github.com/fusionmedialimited/backend-infra-libraries/v3/pkg/http/server/problem"
 	"github.com/fusionmedialimited/backend-infra-libraries/v3/pkg/http/server/validator"
 )
 
+type Config struct {
+	Tracing            bool                   `env:"HTTP_SERVER_TRACING"                   envDefault:"true"                   desc:"Traces incoming requests, spans are named by route templates"`
+	Metrics            bool                   `env:"HTTP_SERVER_METRICS"                   envDefault:"true"                   desc:"Exports <app>_requests_total and other metrics of incoming requests"`
+	ResponseValidation validator.ResponseMode `env:"HTTP_SERVER_RESPONSE_VALIDATION"       envDefault:"off"                    desc:"Validates responses by OpenAPI spec: off, report or enforce"`
+	ResponseSampling   float64                `env:"HTTP_SERVER_RESPONSE_VALIDATION_RATIO" envDefault:"1"  validate:"min=0,max=1" desc:"Ratio of responses validated"`
+	JWKSFile           string                 `env:"HTTP_SERVER_JWKS_FILE"                                                         desc:"JSON Web Key Set file verifying bearer tokens of security schemes"`
+	JWKSURL            string                 `env:"HTTP_SERVER_JWKS_URL"                                                          desc:"JSON Web Key Set URL, used if the file isn't set"`
+	JWKSRefresh        time.Duration          `env:"HTTP_SERVER_JWKS_REFRESH"              envDefault:"5m" validate:"min=1s"       desc:"Reload period of JSON Web Key Set"`
+	JWTAudience        string                 `env:"HTTP_SERVER_JWT_AUDIENCE"                                                      desc:"Required audience of bearer tokens"`
+	JWTIssuer          string                 `env:"HTTP_SERVER_JWT_ISSUER"                                                        desc:"Required issuer of bearer tokens"`
+	JWTOptionalExp     bool                   `env:"HTTP_SERVER_JWT_OPTIONAL_EXP"                                                  desc:"Accepts bearer tokens without exp claim"`
+	APIKeys            string                 `env:"HTTP_SERVER_API_KEYS"                  secret:"true"                           desc:"Comma separated subject:key pairs of API keys"`
+	ClientCertNames    []string               `env:"HTTP_SERVER_CLIENT_CERT_NAMES"                                                 desc:"Common names of client certificates allowed by mutualTLS schemes"`
+	RequestID          bool                   `env:"HTTP_SERVER_REQUEST_ID"                envDefault:"true"                   desc:"Generates X-Request-Id of incoming requests without one"`
+	Propagation        bool                   `env:"HTTP_SERVER_PROPAGATION"               envDefault:"true"                   desc:"Puts headers of incoming requests into the request context for clients"`
+	PropagateHeaders   []string               `env:"HTTP_SERVER_PROPAGATE_HEADERS"         envDefault:"X-Request-Id,X-Tenant-Id,X-User-Id,Accept-Language" desc:"Headers of incoming requests put into the request context"`
+}
+
+func (c Config) Validate() error {
+	switch c.ResponseValidation {
+	case validator.ResponseOff, validator.ResponseReport, validator.ResponseEnforce:
+		return nil
+	default:
+		return config.NewFieldError("ResponseValidation", fmt.Errorf("unknown mode %q", c.ResponseValidation))
+	}
+}
+
+// Setup registers *echo.Echo and its dependencies, prometheus.Registerer may be replaced after Setup.
 func Setup(ctx context.Context, c *di.Container, spec *openapi3.T) {
+	config.Register(Config{}, "")
+	di.Set(c, di.OptInit(func() (conf Config, _ error) {
+		err := config.Parse(&conf, "")
+		return conf, err
+	}))
+	di.Set(c, di.OptInit(func() (prometheus.Registerer, error) {
+		return prometheus.NewRegistry(), nil
+	}))
+	di.Set(c, di.OptInit(func() (prometheus.Gatherer, error) {
+		// collectors of clients, storages and the Go runtime stay on the default registry
+		gatherer, ok := di.Get[prometheus.Registerer](c).(prometheus.Gatherer)
+		if !ok || gatherer == prometheus.DefaultGatherer {
+			return prometheus.DefaultGatherer, nil
+		}
+		return prometheus.Gatherers{gatherer, prometheus.DefaultGatherer}, nil
+	}))
+	di.Set(c, di.OptInit(func() (*problem.Registry, error) {
+		return problem.NewRegistry(), nil
+	}))
+	di.Set(c, di.OptInit(func() (validator.Authenticators, error) {
+		return NewAuthenticators(di.Get[Config](c))
+	}))
 	di.Set(c, di.OptInit(func() (*Readiness, error) {
 		return new(Readiness), nil
 	}))
@@ -29,32 +86,63 @@ func Setup(ctx context.Context, c *di.Container, spec *openapi3.T) {
 		)
 		var (
 			name = di.GetNamed[string](c, config.AppName)
-			p    = prometheus.NewPrometheus(name, nil)
+			conf = di.Get[Config](c)
 			e    = echo.New()
 			log  = di.Get[*zap.Logger](c)
-			//tracerProvider = di.Get[trace.TracerProvider](c)
+			errs = problem.NewErrorHandler(di.Get[*problem.Registry](c))
 		)
-		p.RequestCounterURLLabelMappingFunc = func(c echo.Context) string {
-			p := c.Path() // contains route path ala `/users/:id`
-			if p != "" {
-				return p
-			}
-			// https://github.com/labstack/echo-contrib/blob/master/prometheus/prometheus.go#L201
-			return "unknown"
-		}
 		e.Logger = NewEchoZapLogger(log)
 		e.HideBanner = true
-		//e.Use(middleware.CORS(), p.HandlerFunc)
-		//e.Use(otelecho.Middleware(name, otelecho.WithTracerProvider(tracerProvider)))
+		//e.Use(middleware.CORS())
+		if conf.Metrics {
+			metrics, err := echoprometheus.MiddlewareConfig{
+				Subsystem:  name,
+				Registerer: di.Get[prometheus.Registerer](c),
+				LabelFuncs: map[string]echoprometheus.LabelValueFunc{
+					"url": func(c echo.Context, _ error) string { return routeTemplate(c) },
+				},
+			}.ToMiddleware()
+			if err != nil {
+				return nil, err
+			}
+			e.Use(metrics)
+		}
+		if conf.Tracing {
+			e.Use(otelecho.Middleware(name, otelecho.WithTracerProvider(di.Get[trace.TracerProvider](c))))
+		}
 		e.Use(middleware.Recover())
+		if conf.RequestID {
+			e.Use(middleware.RequestID())
+		}
+		if conf.Propagation {
+			e.Use(NewPropagationMiddlewareFunc(conf.PropagateHeaders...))
+		}
 		e.HTTPErrorHandler = func(err error, c echo.Context) {
-			//ctx := c.Request().Context()
-			//trace.SpanFromContext(ctx).RecordError(err)
+			errs(err, c)
 
-			e.DefaultHTTPErrorHandler(err, c)
+			code := c.Response().Status
+			trace.SpanFromContext(c.Request().Context()).RecordError(err, trace.WithAttributes(semconv.HTTPStatusCode(code)))
 		}
 		if spec != nil {
-			e.Use(validator.NewMiddlewareFunc(spec)) //nolint:contextcheck // uses the context from the request
+			violations := prometheus.NewCounterVec(prometheus.CounterOpts{
+				Namespace: name,
+				Name:      "response_contract_violations_total",
+				Help:      "A counter for responses violating OpenAPI spec.",
+			}, []string{"method", "route"})
+			if err := di.Get[prometheus.Registerer](c).Register(violations); err != nil {
+				return nil, err
+			}
+
+			opts := []validator.OptionFunc{
+				validator.WithResponseValidation(conf.ResponseValidation, conf.ResponseSampling),
+				validator.WithLogger(log),
+				validator.WithViolationsCounter(violations),
+			}
+			if auth := di.Get[validator.Authenticators](c); len(auth) > 0 {
+				opts = append(opts, validator.WithAuthentication(auth))
+			}
+
+			e.Use(validator.NewMiddlewareFunc(spec, opts...)) //nolint:contextcheck // uses the context from the request
 		}
 
 		e.Server.BaseContext = func(_ net.Listener) context.Context { return ctx }
@@ -72,3 +160,11 @@ func Setup(ctx context.Context, c *di.Container, spec *openapi3.T) {
 		return nil
 	}))
 }
+
+func routeTemplate(c echo.Context) string {
+	if path := c.Path(); path != "" {
+		return path
+	}
+
+	return "unknown"
+}
@@ -0,0 +1,132 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	sdk "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+
+	"github.com/labstack/echo/v4"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"github.com/fusionmedialimited/backend-infra-libraries/v3/pkg/config"
+	"github.com/fusionmedialimited/backend-infra-libraries/v3/pkg/di"
+)
+
+func TestSetupObservability(t *testing.T) {
+	var (
+		c        = di.New()
+		recorder = tracetest.NewSpanRecorder()
+		registry = prometheus.NewRegistry()
+	)
+	Setup(context.Background(), c, nil)
+	di.SetNamed(c, config.AppName, di.OptInit(func() (string, error) { return "test", nil }))
+	di.Set(c, di.OptInit(func() (*zap.Logger, error) { return zap.NewNop(), nil }))
+	di.Set(c, di.OptInit(func() (trace.TracerProvider, error) {
+		return sdk.NewTracerProvider(sdk.WithSpanProcessor(recorder)), nil
+	}))
+	di.Set(c, di.OptInit(func() (prometheus.Registerer, error) { return registry, nil }))
+
+	e := di.Get[*echo.Echo](c)
+	e.GET("/users/:id", func(c echo.Context) error {
+		return echo.NewHTTPError(http.StatusNotFound, "no user")
+	})
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/users/42", http.NoBody))
+
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+
+	spans := recorder.Ended()
+	if len(spans) != 1 || spans[0].Name() != "/users/:id" {
+		t.Fatalf("spans = %v, want one /users/:id", spans)
+	}
+
+	var status bool
+	for _, attr := range spans[0].Attributes() {
+		status = status || attr == semconv.HTTPStatusCode(http.StatusNotFound)
+	}
+	if !status || len(spans[0].Events()) != 1 || spans[0].Events()[0].Name != "exception" {
+		t.Errorf("span attributes = %v, events = %v, want status and error", spans[0].Attributes(), spans[0].Events())
+	}
+
+	want := `
+# HELP test_requests_total How many HTTP requests processed, partitioned by status code and HTTP method.
+# TYPE test_requests_total counter
+test_requests_total{code="404",host="example.com",method="GET",url="/users/:id"} 1
+`
+	if err := testutil.GatherAndCompare(registry, strings.NewReader(want), "test_requests_total"); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestSetupGatherer(t *testing.T) {
+	var (
+		c        = di.New()
+		registry = prometheus.NewRegistry()
+	)
+	Setup(context.Background(), c, nil)
+	di.SetNamed(c, config.AppName, di.OptInit(func() (string, error) { return "test", nil }))
+	di.Set(c, di.OptInit(func() (*zap.Logger, error) { return zap.NewNop(), nil }))
+	di.Set(c, di.OptInit(func() (trace.TracerProvider, error) { return trace.NewNoopTracerProvider(), nil }))
+	di.Set(c, di.OptInit(func() (prometheus.Registerer, error) { return registry, nil }))
+
+	e := di.Get[*echo.Echo](c)
+	e.GET("/ping", func(c echo.Context) error { return c.NoContent(http.StatusOK) })
+	e.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/ping", http.NoBody))
+
+	// the introspection server serves the gatherer of the container
+	rec := httptest.NewRecorder()
+	promhttp.HandlerFor(di.Get[prometheus.Gatherer](c), promhttp.HandlerOpts{}).
+		ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", http.NoBody))
+
+	for _, want := range []string{`test_requests_total{code="200",host="example.com",method="GET",url="/ping"} 1`, "go_goroutines"} {
+		if !strings.Contains(rec.Body.String(), want) {
+			t.Errorf("metrics = %s, want %s", rec.Body.String(), want)
+		}
+	}
+}
+
+func TestSetupRegisterer(t *testing.T) {
+	c := di.New()
+	Setup(context.Background(), c, nil)
+
+	if di.Get[prometheus.Registerer](c) == prometheus.DefaultRegisterer {
+		t.Error("default registerer is the global one, want own registry")
+	}
+}
+
+func TestSetupRequestID(t *testing.T) {
+	for _, enabled := range []bool{true, false} {
+		if !enabled {
+			t.Setenv("HTTP_SERVER_REQUEST_ID", "false")
+		}
+
+		c := di.New()
+		Setup(context.Background(), c, nil)
+		di.SetNamed(c, config.AppName, di.OptInit(func() (string, error) { return "test", nil }))
+		di.Set(c, di.OptInit(func() (*zap.Logger, error) { return zap.NewNop(), nil }))
+		di.Set(c, di.OptInit(func() (trace.TracerProvider, error) { return trace.NewNoopTracerProvider(), nil }))
+		di.Set(c, di.OptInit(func() (prometheus.Registerer, error) { return prometheus.NewRegistry(), nil }))
+
+		e := di.Get[*echo.Echo](c)
+		e.GET("/ping", func(c echo.Context) error { return c.NoContent(http.StatusOK) })
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/ping", http.NoBody))
+
+		if got := rec.Header().Get(echo.HeaderXRequestID) != ""; got != enabled {
+			t.Errorf("request ID generated = %v, want %v", got, enabled)
+		}
+	}
+}
@@ -0,0 +1,141 @@
+package problem
+
+import (
+	"errors"
+	"net/http"
+	"sync"
+
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/labstack/echo/v4"
+
+	httpproblem "github.com/fusionmedialimited/backend-infra-libraries/v3/pkg/http/problem"
+	"github.com/fusionmedialimited/backend-infra-libraries/v3/pkg/primitives"
+)
+
+const MIMEApplicationProblem = httpproblem.MIMEApplicationProblem
+
+type (
+	// Problem is shared with the client, see client.HTTPError.
+	Problem    = httpproblem.Problem
+	FieldError = httpproblem.FieldError
+
+	Registry struct {
+		mu       sync.RWMutex
+		mappings []func(err error) (*Problem, bool)
+	}
+)
+
+func New(status int, detail string) *Problem {
+	return httpproblem.New(status, detail)
+}
+
+func NewRegistry() *Registry {
+	return new(Registry)
+}
+
+func (r *Registry) Map(target error, status int, title string) {
+	r.add(func(err error) (*Problem, bool) {
+		if !errors.Is(err, target) {
+			return nil, false
+		}
+
+		return newMapped(status, title, err), true
+	})
+}
+
+func MapAs[T error](r *Registry, status int, title string) {
+	r.add(func(err error) (*Problem, bool) {
+		var target T
+		if !errors.As(err, &target) {
+			return nil, false
+		}
+
+		return newMapped(status, title, err), true
+	})
+}
+
+func (r *Registry) add(mapping func(err error) (*Problem, bool)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.mappings = append(r.mappings, mapping)
+}
+
+// Problem converts the error, details of 5xx errors are hidden unless it's *Problem.
+func (r *Registry) Problem(err error) *Problem {
+	if p := new(Problem); errors.As(err, &p) {
+		return p
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, mapping := range r.mappings {
+		if p, ok := mapping(err); ok {
+			return p
+		}
+	}
+
+	if httpErr := new(echo.HTTPError); errors.As(err, &httpErr) {
+		p := New(httpErr.Code, "")
+		if msg, ok := httpErr.Message.(string); ok && httpErr.Code < http.StatusInternalServerError {
+			p.Detail = msg
+		}
+
+		return p
+	}
+
+	return New(http.StatusInternalServerError, "")
+}
+
+func NewErrorHandler(r *Registry) echo.HTTPErrorHandler {
+	return func(err error, c echo.Context) {
+		if c.Response().Committed {
+			return
+		}
+
+		p := r.Problem(err)
+		if p.Status >= http.StatusInternalServerError {
+			c.Logger().Error(err)
+		}
+
+		if err = Write(c, p); err != nil {
+			c.Logger().Error(err)
+		}
+	}
+}
+
+func Write(c echo.Context, p *Problem) error {
+	resp := *p
+	req := c.Request()
+	if resp.Instance == "" {
+		resp.Instance = req.URL.Path
+	}
+	if spanContext := trace.SpanContextFromContext(req.Context()); resp.TraceID == "" && spanContext.HasTraceID() {
+		resp.TraceID = spanContext.TraceID().String()
+	}
+
+	if req.Method == http.MethodHead {
+		return c.NoContent(resp.Status)
+	}
+
+	data, err := primitives.MarshalJSON(resp)
+	if err != nil {
+		return err
+	}
+
+	return c.Blob(resp.Status, MIMEApplicationProblem, data)
+}
+
+func newMapped(status int, title string, err error) *Problem {
+	p := New(status, "")
+	if title != "" {
+		p.Title = title
+	}
+	if status < http.StatusInternalServerError {
+		p.Detail = err.Error()
+	}
+
+	return p
+}
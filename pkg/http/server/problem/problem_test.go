@@ -0,0 +1,76 @@
+package problem
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/labstack/echo/v4"
+)
+
+var errNotFound = errors.New("user not found")
+
+type conflictError struct{ id int }
+
+func (e conflictError) Error() string { return fmt.Sprintf("user %d exists", e.id) }
+
+func TestErrorHandler(t *testing.T) {
+	registry := NewRegistry()
+	registry.Map(errNotFound, http.StatusNotFound, "")
+	MapAs[conflictError](registry, http.StatusConflict, "User conflict")
+
+	e := echo.New()
+	e.HTTPErrorHandler = NewErrorHandler(registry)
+
+	traceID := trace.TraceID{1}
+	for _, tc := range []struct {
+		err  error
+		want Problem
+	}{
+		{
+			err:  fmt.Errorf("get: %w", errNotFound),
+			want: Problem{Title: "Not Found", Status: http.StatusNotFound, Detail: "get: user not found"},
+		},
+		{
+			err:  conflictError{id: 1},
+			want: Problem{Title: "User conflict", Status: http.StatusConflict, Detail: "user 1 exists"},
+		},
+		{
+			err:  echo.NewHTTPError(http.StatusBadRequest, "bad id"),
+			want: Problem{Title: "Bad Request", Status: http.StatusBadRequest, Detail: "bad id"},
+		},
+		{
+			err:  errors.New("connection refused"),
+			want: Problem{Title: "Internal Server Error", Status: http.StatusInternalServerError},
+		},
+		{
+			err: &Problem{Title: "Payment required", Status: http.StatusPaymentRequired,
+				Errors: []FieldError{{Field: "card", Message: "expired"}}},
+			want: Problem{Title: "Payment required", Status: http.StatusPaymentRequired,
+				Errors: []FieldError{{Field: "card", Message: "expired"}}},
+		},
+	} {
+		req := httptest.NewRequest(http.MethodGet, "/users/1", http.NoBody)
+		req = req.WithContext(trace.ContextWithSpanContext(req.Context(), trace.NewSpanContext(trace.SpanContextConfig{
+			TraceID: traceID,
+		})))
+		rec := httptest.NewRecorder()
+		e.HTTPErrorHandler(tc.err, e.NewContext(req, rec))
+
+		var got Problem
+		if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+			t.Fatal(err)
+		}
+
+		tc.want.Instance, tc.want.TraceID = "/users/1", traceID.String()
+		if rec.Code != tc.want.Status || rec.Header().Get("Content-Type") != MIMEApplicationProblem ||
+			fmt.Sprint(got) != fmt.Sprint(tc.want) {
+			t.Errorf("%v: %d %s %+v, want %+v", tc.err, rec.Code, rec.Header().Get("Content-Type"), got, tc.want)
+		}
+	}
+}
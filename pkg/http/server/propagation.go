@@ -0,0 +1,36 @@
+package server
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/fusionmedialimited/backend-infra-libraries/v3/pkg/http/propagation"
+)
+
+// NewPropagationMiddlewareFunc puts the headers of incoming requests into the request context.
+func NewPropagationMiddlewareFunc(headers ...string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+			propagated := make(http.Header, len(headers))
+			for _, name := range headers {
+				name = http.CanonicalHeaderKey(name)
+				values := req.Header.Values(name)
+				if len(values) == 0 && name == echo.HeaderXRequestID {
+					values = c.Response().Header().Values(name)
+				}
+
+				if len(values) > 0 {
+					propagated[name] = values
+				}
+			}
+
+			if len(propagated) > 0 {
+				c.SetRequest(req.WithContext(propagation.WithHeaders(req.Context(), propagated)))
+			}
+
+			return next(c)
+		}
+	}
+}
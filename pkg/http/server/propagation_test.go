@@ -0,0 +1,70 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/fusionmedialimited/backend-infra-libraries/v3/pkg/http/client"
+)
+
+func TestPropagationMiddleware(t *testing.T) {
+	var downstream http.Header
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		downstream = r.Header.Clone()
+	}))
+	defer srv.Close()
+
+	httpClient, err := client.NewClient("test",
+		client.WithPrometheusRegisterer(prometheus.NewRegistry()),
+		client.WithHeaderPropagation(),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	e := echo.New()
+	e.Use(middleware.RequestID(), NewPropagationMiddlewareFunc(client.DefaultPropagatedHeaders...))
+	e.GET("/", func(c echo.Context) error {
+		req, _ := http.NewRequestWithContext(c.Request().Context(), http.MethodGet, srv.URL, http.NoBody)
+		req.Header.Set("Accept-Language", "de")
+
+		resp, err := httpClient.Do(req)
+		if err != nil {
+			return err
+		}
+		_ = resp.Body.Close()
+
+		return c.NoContent(http.StatusNoContent)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/", http.NoBody)
+	req.Header.Set("X-Tenant-Id", "acme")
+	req.Header.Set("Accept-Language", "en")
+	req.Header.Set("X-Secret", "secret")
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusNoContent)
+	}
+
+	for name, want := range map[string]string{
+		"X-Request-Id":    rec.Header().Get(echo.HeaderXRequestID),
+		"X-Tenant-Id":     "acme",
+		"Accept-Language": "de",
+		"X-Secret":        "",
+	} {
+		if got := downstream.Get(name); got != want {
+			t.Errorf("downstream %s = %q, want %q", name, got, want)
+		}
+	}
+
+	if downstream.Get("X-Request-Id") == "" {
+		t.Error("request ID isn't propagated")
+	}
+}
@@ -0,0 +1,41 @@
+package server
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/fusionmedialimited/backend-infra-libraries/v3/pkg/http/signature"
+)
+
+// NewSignatureMiddlewareFunc verifies requests signed by signature.HMACSigner, bodies over maxBody,
+// 10MB if it's not positive, are rejected before verification.
+func NewSignatureMiddlewareFunc(keys func(keyID string) ([]byte, bool), skew time.Duration, maxBody int64) echo.MiddlewareFunc {
+	if maxBody <= 0 {
+		maxBody = signature.MaxBody
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+			body, err := io.ReadAll(http.MaxBytesReader(c.Response(), req.Body, maxBody))
+			if tooLarge := new(http.MaxBytesError); errors.As(err, &tooLarge) {
+				return echo.NewHTTPError(http.StatusRequestEntityTooLarge, http.StatusText(http.StatusRequestEntityTooLarge)).SetInternal(err)
+			}
+			if err != nil {
+				return echo.NewHTTPError(http.StatusBadRequest, "cannot read body").SetInternal(err)
+			}
+			req.Body = io.NopCloser(bytes.NewReader(body))
+
+			if err = signature.VerifyHMAC(req, body, keys, skew); err != nil {
+				return echo.NewHTTPError(http.StatusUnauthorized, http.StatusText(http.StatusUnauthorized)).SetInternal(err)
+			}
+
+			return next(c)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/fusionmedialimited/backend-infra-libraries/v3/pkg/http/client"
+	"github.com/fusionmedialimited/backend-infra-libraries/v3/pkg/http/signature"
+)
+
+func TestSignatureMiddleware(t *testing.T) {
+	e := echo.New()
+	e.Use(NewSignatureMiddlewareFunc(func(keyID string) ([]byte, bool) {
+		return []byte("secret"), keyID == "svc"
+	}, time.Minute, 16))
+	e.POST("/orders", func(c echo.Context) error { return c.NoContent(http.StatusCreated) })
+
+	srv := httptest.NewServer(e)
+	defer srv.Close()
+
+	for _, tc := range []struct {
+		signer client.RequestSigner
+		want   int
+	}{
+		{signature.HMACSigner{KeyID: "svc", Key: []byte("secret")}, http.StatusCreated},
+		{signature.HMACSigner{KeyID: "svc", Key: []byte("wrong")}, http.StatusUnauthorized},
+		{signature.HMACSigner{KeyID: "other", Key: []byte("secret")}, http.StatusUnauthorized},
+	} {
+		c, err := client.NewClient("test",
+			client.WithPrometheusRegisterer(prometheus.NewRegistry()),
+			client.WithRequestSigner(tc.signer),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		resp, err := c.Post(srv.URL+"/orders?x=1", "application/json", strings.NewReader(`{"id":1}`))
+		if err != nil {
+			t.Fatal(err)
+		}
+		_ = resp.Body.Close()
+
+		if resp.StatusCode != tc.want {
+			t.Errorf("signer %+v: status = %d, want %d", tc.signer, resp.StatusCode, tc.want)
+		}
+	}
+
+	resp, err := http.Post(srv.URL+"/orders", "application/json", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("unsigned request status = %d, want 401", resp.StatusCode)
+	}
+
+	resp, err = http.Post(srv.URL+"/orders", "application/json", strings.NewReader(strings.Repeat("x", 17)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+
+	if resp.StatusCode != http.StatusRequestEntityTooLarge {
+		t.Errorf("oversized request status = %d, want 413", resp.StatusCode)
+	}
+}
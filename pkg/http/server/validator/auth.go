@@ -0,0 +1,244 @@
+package validator
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/labstack/echo/v4"
+)
+
+const PrincipalKey = "principal"
+
+var (
+	// ErrUnauthenticated is responded by 401, other errors of security requirements by 403.
+	ErrUnauthenticated   = errors.New("unauthenticated")
+	ErrInsufficientScope = errors.New("insufficient scope")
+	// ErrNoAuthenticator is a misconfiguration, it's responded by 500.
+	ErrNoAuthenticator = errors.New("no authenticator")
+)
+
+type (
+	Principal struct {
+		Scheme  string
+		Subject string
+		Scopes  []string
+		Claims  map[string]any
+	}
+
+	Authenticator func(ctx context.Context, input *openapi3filter.AuthenticationInput) (*Principal, error)
+
+	// Authenticators are keyed by names of security schemes, by schemes of http type (bearer, basic) or by types.
+	Authenticators map[string]Authenticator
+
+	principalKey struct{}
+)
+
+func PrincipalFromContext(c echo.Context) (*Principal, bool) {
+	principal, ok := c.Get(PrincipalKey).(*Principal)
+	return principal, ok
+}
+
+func (a Authenticators) AuthenticationFunc() openapi3filter.AuthenticationFunc {
+	return func(ctx context.Context, input *openapi3filter.AuthenticationInput) error {
+		authenticate, ok := a[input.SecuritySchemeName]
+		if scheme := input.SecurityScheme; !ok && scheme != nil {
+			if scheme.Type == "http" {
+				authenticate, ok = a[strings.ToLower(scheme.Scheme)]
+			}
+			if !ok {
+				authenticate, ok = a[scheme.Type]
+			}
+		}
+		if !ok {
+			return fmt.Errorf("%w of %q scheme", ErrNoAuthenticator, input.SecuritySchemeName)
+		}
+
+		principal, err := authenticate(ctx, input)
+		if err != nil {
+			return err
+		}
+
+		if principal.Scheme == "" {
+			principal.Scheme = input.SecuritySchemeName
+		}
+		if holder, ok := ctx.Value(principalKey{}).(**Principal); ok {
+			*holder = principal
+		}
+
+		return nil
+	}
+}
+
+type JWTOptionFunc func(config *jwtConfig)
+
+type jwtConfig struct {
+	optionalExpiration bool
+}
+
+// WithOptionalExpiration accepts tokens without exp claim.
+func WithOptionalExpiration() JWTOptionFunc {
+	return func(config *jwtConfig) {
+		config.optionalExpiration = true
+	}
+}
+
+// JWTAuthenticator verifies bearer tokens by the key set, empty audience and issuer aren't checked.
+func JWTAuthenticator(keys *JWKS, audience, issuer string, opts ...JWTOptionFunc) Authenticator {
+	var conf jwtConfig
+	for _, opt := range opts {
+		opt(&conf)
+	}
+
+	parserOpts := []jwt.ParserOption{jwt.WithValidMethods([]string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512"})}
+	if !conf.optionalExpiration {
+		parserOpts = append(parserOpts, jwt.WithExpirationRequired())
+	}
+	if audience != "" {
+		parserOpts = append(parserOpts, jwt.WithAudience(audience))
+	}
+	if issuer != "" {
+		parserOpts = append(parserOpts, jwt.WithIssuer(issuer))
+	}
+
+	return func(ctx context.Context, input *openapi3filter.AuthenticationInput) (*Principal, error) {
+		scheme, token, _ := strings.Cut(input.RequestValidationInput.Request.Header.Get(echo.HeaderAuthorization), " ")
+		if !strings.EqualFold(scheme, "Bearer") || token == "" {
+			return nil, fmt.Errorf("%w: no bearer token", ErrUnauthenticated)
+		}
+
+		claims := jwt.MapClaims{}
+		_, err := jwt.ParseWithClaims(token, claims, func(token *jwt.Token) (any, error) {
+			kid, _ := token.Header["kid"].(string)
+			return keys.Key(ctx, kid)
+		}, parserOpts...)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrUnauthenticated, err)
+		}
+
+		granted := tokenScopes(claims)
+		for _, scope := range input.Scopes {
+			if !contains(granted, scope) {
+				return nil, fmt.Errorf("%w: %s", ErrInsufficientScope, scope)
+			}
+		}
+
+		subject, _ := claims["sub"].(string)
+		return &Principal{Subject: subject, Scopes: granted, Claims: claims}, nil
+	}
+}
+
+// APIKeyAuthenticator looks up subjects by keys placed as the scheme defines.
+func APIKeyAuthenticator(keys map[string]string) Authenticator {
+	return func(_ context.Context, input *openapi3filter.AuthenticationInput) (*Principal, error) {
+		var (
+			req    = input.RequestValidationInput.Request
+			scheme = input.SecurityScheme
+			key    string
+		)
+		switch scheme.In {
+		case "header":
+			key = req.Header.Get(scheme.Name)
+		case "query":
+			key = req.URL.Query().Get(scheme.Name)
+		case "cookie":
+			if cookie, err := req.Cookie(scheme.Name); err == nil {
+				key = cookie.Value
+			}
+		default:
+			return nil, fmt.Errorf("unsupported API key location %q", scheme.In)
+		}
+		if key == "" {
+			return nil, fmt.Errorf("%w: no API key", ErrUnauthenticated)
+		}
+
+		// all keys are compared to not leak which of them is close
+		subject, found := "", false
+		for known, owner := range keys {
+			if subtle.ConstantTimeCompare([]byte(known), []byte(key)) == 1 {
+				subject, found = owner, true
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("%w: unknown API key", ErrUnauthenticated)
+		}
+
+		return &Principal{Subject: subject}, nil
+	}
+}
+
+// ClientCertAuthenticator accepts verified client certificates of the common names, any if they're empty.
+func ClientCertAuthenticator(names ...string) Authenticator {
+	return func(_ context.Context, input *openapi3filter.AuthenticationInput) (*Principal, error) {
+		state := input.RequestValidationInput.Request.TLS
+		if state == nil || len(state.VerifiedChains) == 0 || len(state.VerifiedChains[0]) == 0 {
+			return nil, fmt.Errorf("%w: no verified client certificate", ErrUnauthenticated)
+		}
+
+		subject := state.VerifiedChains[0][0].Subject.CommonName
+		if len(names) > 0 && !contains(names, subject) {
+			return nil, fmt.Errorf("%w: certificate %q isn't allowed", ErrUnauthenticated, subject)
+		}
+
+		return &Principal{Subject: subject}, nil
+	}
+}
+
+// WithAuthentication enforces security requirements of the spec, see PrincipalFromContext.
+func WithAuthentication(authenticators Authenticators) OptionFunc {
+	return WithAuthenticationFunc(authenticators.AuthenticationFunc())
+}
+
+func WithAuthenticationFunc(fn openapi3filter.AuthenticationFunc) OptionFunc {
+	return func(config *Config) {
+		config.Authentication = fn
+	}
+}
+
+func securityStatus(err *openapi3filter.SecurityRequirementsError) int {
+	status := http.StatusUnauthorized
+	for _, err := range err.Errors {
+		switch {
+		case errors.Is(err, ErrNoAuthenticator):
+			return http.StatusInternalServerError
+		case !errors.Is(err, ErrUnauthenticated):
+			status = http.StatusForbidden
+		}
+	}
+
+	return status
+}
+
+func tokenScopes(claims jwt.MapClaims) []string {
+	switch scopes := claims["scp"].(type) {
+	case []any:
+		granted := make([]string, 0, len(scopes))
+		for _, scope := range scopes {
+			if scope, ok := scope.(string); ok {
+				granted = append(granted, scope)
+			}
+		}
+
+		return granted
+	case string:
+		return strings.Fields(scopes)
+	}
+
+	scope, _ := claims["scope"].(string)
+	return strings.Fields(scope)
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,234 @@
+package validator_test
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/labstack/echo/v4"
+	"github.com/matryer/is"
+
+	"github.com/fusionmedialimited/backend-infra-libraries/v3/pkg/http/server/validator"
+)
+
+const securedSpec = `
+openapi: 3.0.0
+info: {title: test, version: "1"}
+components:
+  securitySchemes:
+    bearer: {type: http, scheme: bearer}
+    key: {type: apiKey, in: header, name: X-Api-Key}
+paths:
+  /orders:
+    get:
+      security: [{bearer: [orders.read]}, {key: []}]
+      responses:
+        "200": {description: ok}
+`
+
+func TestAuthentication(t *testing.T) {
+	isLib := is.New(t)
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	isLib.NoErr(err)
+
+	encode := func(n *big.Int) string { return base64.RawURLEncoding.EncodeToString(n.Bytes()) }
+	jwks := `{"keys":[{"kty":"RSA","kid":"k1","use":"sig","n":"` + encode(key.N) + `","e":"` + encode(big.NewInt(int64(key.E))) + `"}]}`
+	file := filepath.Join(t.TempDir(), "jwks.json")
+	isLib.NoErr(os.WriteFile(file, []byte(jwks), 0o600))
+
+	sign := func(claims jwt.MapClaims) string {
+		token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+		token.Header["kid"] = "k1"
+		signed, err := token.SignedString(key)
+		isLib.NoErr(err)
+		return "Bearer " + signed
+	}
+	exp := time.Now().Add(time.Hour).Unix()
+
+	spec, err := openapi3.NewLoader().LoadFromData([]byte(securedSpec))
+	isLib.NoErr(err)
+
+	e := echo.New()
+	e.Use(validator.NewMiddlewareFunc(spec, validator.WithAuthentication(validator.Authenticators{
+		"bearer": validator.JWTAuthenticator(validator.NewJWKSFromFile(file, time.Minute), "orders", "issuer"),
+		"apiKey": validator.APIKeyAuthenticator(map[string]string{"secret": "billing"}),
+	})))
+	e.GET("/orders", func(c echo.Context) error {
+		principal, ok := validator.PrincipalFromContext(c)
+		if !ok {
+			return echo.ErrInternalServerError
+		}
+		return c.String(http.StatusOK, principal.Scheme+":"+principal.Subject)
+	})
+
+	tests := []struct {
+		name   string
+		header string
+		value  string
+		code   int
+		body   string
+	}{
+		{"no credentials", "", "", http.StatusUnauthorized, ""},
+		{"token", "Authorization", sign(jwt.MapClaims{"sub": "u1", "aud": "orders", "iss": "issuer", "exp": exp, "scope": "orders.read"}), http.StatusOK, "bearer:u1"},
+		{"no expiration", "Authorization", sign(jwt.MapClaims{"sub": "u1", "aud": "orders", "iss": "issuer", "scope": "orders.read"}), http.StatusUnauthorized, ""},
+		{"expired token", "Authorization", sign(jwt.MapClaims{"sub": "u1", "aud": "orders", "iss": "issuer", "exp": 1, "scope": "orders.read"}), http.StatusUnauthorized, ""},
+		{"wrong audience", "Authorization", sign(jwt.MapClaims{"sub": "u1", "aud": "other", "iss": "issuer", "exp": exp, "scope": "orders.read"}), http.StatusUnauthorized, ""},
+		{"no scope", "Authorization", sign(jwt.MapClaims{"sub": "u1", "aud": "orders", "iss": "issuer", "exp": exp}), http.StatusForbidden, ""},
+		{"API key", "X-Api-Key", "secret", http.StatusOK, "key:billing"},
+		{"unknown API key", "X-Api-Key", "guess", http.StatusUnauthorized, ""},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			isLib := is.New(t)
+
+			req := httptest.NewRequest(http.MethodGet, host+"/orders", nil)
+			if test.header != "" {
+				req.Header.Set(test.header, test.value)
+			}
+			res := httptest.NewRecorder()
+			e.ServeHTTP(res, req)
+
+			isLib.Equal(test.code, res.Code)
+			if test.body != "" {
+				isLib.Equal(test.body, res.Body.String())
+			}
+		})
+	}
+}
+
+func TestNoAuthenticator(t *testing.T) {
+	isLib := is.New(t)
+
+	spec, err := openapi3.NewLoader().LoadFromData([]byte(`
+openapi: 3.0.0
+info: {title: test, version: "1"}
+components:
+  securitySchemes:
+    basic: {type: http, scheme: basic}
+paths:
+  /orders:
+    get:
+      security: [{basic: []}]
+      responses:
+        "200": {description: ok}
+`))
+	isLib.NoErr(err)
+
+	e := echo.New()
+	e.Use(validator.NewMiddlewareFunc(spec, validator.WithAuthentication(validator.Authenticators{
+		"bearer": func(context.Context, *openapi3filter.AuthenticationInput) (*validator.Principal, error) {
+			return &validator.Principal{}, nil
+		},
+	})))
+	e.GET("/orders", func(c echo.Context) error { return c.NoContent(http.StatusOK) })
+
+	res := httptest.NewRecorder()
+	e.ServeHTTP(res, httptest.NewRequest(http.MethodGet, host+"/orders", nil))
+
+	isLib.Equal(http.StatusInternalServerError, res.Code)
+}
+
+func TestJWKSReload(t *testing.T) {
+	isLib := is.New(t)
+
+	served := `{"keys":[]}`
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(served))
+	}))
+	defer srv.Close()
+
+	keys := validator.NewJWKSFromURL(srv.URL, srv.Client(), time.Hour)
+	_, err := keys.Key(context.Background(), "k1")
+	isLib.True(err != nil) // unknown key
+
+	// the set is cached, a rotated key isn't reloaded till the refresh
+	served = `{"keys":[{"kty":"EC","kid":"k1","crv":"P-256","x":"AQ","y":"Ag"}]}`
+	_, err = keys.Key(context.Background(), "k1")
+	isLib.True(err != nil)
+}
+
+func TestJWKSRefresh(t *testing.T) {
+	isLib := is.New(t)
+
+	var (
+		requests atomic.Int32
+		release  = make(chan struct{})
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		if requests.Add(1) > 1 {
+			<-release
+		}
+		_, _ = w.Write([]byte(`{"keys":[{"kty":"EC","kid":"k1","crv":"P-256","x":"AQ","y":"Ag"}]}`))
+	}))
+	defer srv.Close()
+	defer close(release)
+
+	keys := validator.NewJWKSFromURL(srv.URL, srv.Client(), time.Millisecond)
+	_, err := keys.Key(context.Background(), "k1")
+	isLib.NoErr(err)
+
+	// the cached key is returned while the refresh is blocked
+	time.Sleep(time.Millisecond * 5)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		_, err = keys.Key(ctx, "k1")
+		isLib.NoErr(err)
+	}
+	isLib.True(time.Since(start) < time.Millisecond*500)
+}
+
+func TestJWKSCanceledReload(t *testing.T) {
+	isLib := is.New(t)
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	isLib.NoErr(err)
+
+	encode := func(n *big.Int) string { return base64.RawURLEncoding.EncodeToString(n.Bytes()) }
+	jwks := `{"keys":[{"kty":"RSA","kid":"k1","use":"sig","n":"` + encode(key.N) + `","e":"` + encode(big.NewInt(int64(key.E))) + `"}]}`
+
+	requested, release := make(chan struct{}), make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(requested)
+		<-release
+		_, _ = w.Write([]byte(jwks))
+	}))
+	defer srv.Close()
+
+	keys := validator.NewJWKSFromURL(srv.URL, srv.Client(), time.Minute)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	first := make(chan error)
+	go func() {
+		_, err := keys.Key(ctx, "k1")
+		first <- err
+	}()
+	<-requested
+
+	second := make(chan error)
+	go func() {
+		_, err := keys.Key(context.Background(), "k1")
+		second <- err
+	}()
+	time.Sleep(10 * time.Millisecond)
+
+	cancel()
+	isLib.Equal(<-first, context.Canceled)
+
+	close(release)
+	isLib.NoErr(<-second) // the reload isn't canceled with the first caller
+}
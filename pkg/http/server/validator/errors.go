@@ -1,12 +1,86 @@
 package validator
 
-import "github.com/labstack/echo/v4"
+import (
+	"errors"
+	"net/http"
+	"strings"
 
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/labstack/echo/v4"
+
+	"github.com/fusionmedialimited/backend-infra-libraries/v3/pkg/http/server/problem"
+)
+
+// Error is the former body of validation errors.
+//
+// Deprecated: errors are rendered as problem.Problem, match on it instead.
 type Error struct {
 	Code    int    `json:"code"`
 	Message string `json:"message"`
 }
 
-func reportErr(c echo.Context, code int, msg string) error {
-	return c.JSON(code, Error{Code: code, Message: msg})
+func reportErr(c echo.Context, code int, err error) error {
+	p := problem.New(code, err.Error())
+	if code >= http.StatusInternalServerError {
+		c.Logger().Error(err)
+		p.Detail = ""
+	}
+
+	var requestErr *openapi3filter.RequestError
+	if errors.As(err, &requestErr) {
+		p.Errors = fieldErrors(requestErr)
+	}
+
+	return problem.Write(c, p)
+}
+
+func fieldErrors(err *openapi3filter.RequestError) []problem.FieldError {
+	var field string
+	switch {
+	case err.Parameter != nil:
+		field = err.Parameter.In + "." + err.Parameter.Name
+	case err.RequestBody != nil:
+		field = "body"
+	default:
+		return nil
+	}
+
+	schemaErrs := schemaErrors(err.Err, nil)
+	if len(schemaErrs) == 0 {
+		msg := err.Reason
+		if msg == "" && err.Err != nil {
+			msg = err.Err.Error()
+		}
+
+		return []problem.FieldError{{Field: field, Message: msg}}
+	}
+
+	fields := make([]problem.FieldError, 0, len(schemaErrs))
+	for _, schemaErr := range schemaErrs {
+		name := field
+		if pointer := schemaErr.JSONPointer(); len(pointer) > 0 {
+			name += "/" + strings.Join(pointer, "/")
+		}
+		fields = append(fields, problem.FieldError{Field: name, Message: schemaErr.Reason})
+	}
+
+	return fields
+}
+
+func schemaErrors(err error, collected []*openapi3.SchemaError) []*openapi3.SchemaError {
+	var multi openapi3.MultiError
+	if errors.As(err, &multi) {
+		for _, err := range multi {
+			collected = schemaErrors(err, collected)
+		}
+
+		return collected
+	}
+
+	if schemaErr := new(openapi3.SchemaError); errors.As(err, &schemaErr) {
+		collected = append(collected, schemaErr)
+	}
+
+	return collected
 }
@@ -0,0 +1,234 @@
+package validator
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+
+	"github.com/fusionmedialimited/backend-infra-libraries/v3/pkg/primitives"
+)
+
+const (
+	minReload     = time.Second * 10
+	reloadTimeout = time.Second * 30
+)
+
+var ErrUnknownKey = errors.New("unknown key")
+
+type (
+	// JWKS is a JSON Web Key Set, it's reloaded earlier when a token is signed by an unknown key.
+	JWKS struct {
+		load    func(ctx context.Context) ([]byte, error)
+		refresh time.Duration
+		group   singleflight.Group
+
+		mu     sync.Mutex
+		keys   map[string]crypto.PublicKey
+		loaded time.Time
+	}
+
+	jwk struct {
+		Kty string `json:"kty"`
+		Kid string `json:"kid"`
+		Use string `json:"use"`
+		N   string `json:"n"`
+		E   string `json:"e"`
+		Crv string `json:"crv"`
+		X   string `json:"x"`
+		Y   string `json:"y"`
+	}
+)
+
+func NewJWKSFromFile(path string, refresh time.Duration) *JWKS {
+	return &JWKS{
+		load: func(context.Context) ([]byte, error) {
+			return os.ReadFile(path)
+		},
+		refresh: refresh,
+	}
+}
+
+func NewJWKSFromURL(url string, client *http.Client, refresh time.Duration) *JWKS {
+	return &JWKS{
+		load: func(ctx context.Context) ([]byte, error) {
+			req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, http.NoBody)
+			if err != nil {
+				return nil, err
+			}
+
+			resp, err := client.Do(req)
+			if err != nil {
+				return nil, err
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != http.StatusOK {
+				return nil, fmt.Errorf("unexpected status %d", resp.StatusCode)
+			}
+
+			return io.ReadAll(resp.Body)
+		},
+		refresh: refresh,
+	}
+}
+
+func (j *JWKS) Key(ctx context.Context, kid string) (crypto.PublicKey, error) {
+	j.mu.Lock()
+	key, ok := j.lookup(kid)
+	loaded, age := j.keys != nil, time.Since(j.loaded)
+	j.mu.Unlock()
+
+	switch {
+	case ok && age > j.refresh:
+		j.group.DoChan("", func() (any, error) {
+			// it's refreshed in background, so the request context isn't used
+			ctx, cancel := context.WithTimeout(context.Background(), reloadTimeout) //nolint:contextcheck
+			defer cancel()
+
+			return nil, j.reload(ctx)
+		})
+	case !loaded || (!ok && (age > j.refresh || age > minReload)):
+		select {
+		case res := <-j.group.DoChan("", func() (any, error) {
+			// callers share the reload, so it isn't canceled by the first of them
+			ctx, cancel := context.WithTimeout(context.Background(), reloadTimeout) //nolint:contextcheck
+			defer cancel()
+
+			return nil, j.reload(ctx)
+		}):
+			j.mu.Lock()
+			key, ok = j.lookup(kid)
+			loaded = j.keys != nil
+			j.mu.Unlock()
+
+			if res.Err != nil && !loaded {
+				return nil, res.Err
+			}
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	if !ok {
+		return nil, fmt.Errorf("%w %q", ErrUnknownKey, kid)
+	}
+
+	return key, nil
+}
+
+func (j *JWKS) lookup(kid string) (crypto.PublicKey, bool) {
+	if kid == "" && len(j.keys) == 1 {
+		for _, key := range j.keys {
+			return key, true
+		}
+	}
+
+	key, ok := j.keys[kid]
+	return key, ok
+}
+
+func (j *JWKS) reload(ctx context.Context) error {
+	keys, err := j.fetch(ctx)
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	j.loaded = time.Now()
+	if err != nil {
+		return err
+	}
+	j.keys = keys
+
+	return nil
+}
+
+func (j *JWKS) fetch(ctx context.Context) (map[string]crypto.PublicKey, error) {
+	data, err := j.load(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("load JWKS: %w", err)
+	}
+
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err = primitives.UnmarshalJSON(data, &set); err != nil {
+		return nil, fmt.Errorf("parse JWKS: %w", err)
+	}
+
+	keys := make(map[string]crypto.PublicKey, len(set.Keys))
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+
+		key, err := k.publicKey()
+		if err != nil {
+			return nil, fmt.Errorf("parse JWK %q: %w", k.Kid, err)
+		}
+		keys[k.Kid] = key
+	}
+
+	return keys, nil
+}
+
+func (k jwk) publicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+	}
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+
+	return new(big.Int).SetBytes(data), nil
+}
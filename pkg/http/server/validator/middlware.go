@@ -1,6 +1,7 @@
 package validator
 
 import (
+	"context"
 	"net/http"
 	"sort"
 	"strings"
@@ -11,7 +12,13 @@ import (
 	"github.com/labstack/echo/v4"
 )
 
-func NewMiddlewareFunc(spec *openapi3.T) echo.MiddlewareFunc {
+// NewMiddlewareFunc validates requests by the spec, responses are validated with WithResponseValidation.
+func NewMiddlewareFunc(spec *openapi3.T, opts ...OptionFunc) echo.MiddlewareFunc {
+	config := defaultConfig()
+	for _, opt := range opts {
+		opt(&config)
+	}
+
 	pathItemsMap := collectPathItemsMap(spec)
 	return func(nextHandler echo.HandlerFunc) echo.HandlerFunc {
 		return func(c echo.Context) error {
@@ -27,33 +34,41 @@ func NewMiddlewareFunc(spec *openapi3.T) echo.MiddlewareFunc {
 				return nextHandler(c)
 			}
 
-			err := openapi3filter.ValidateRequest(
-				req.Context(),
-				&openapi3filter.RequestValidationInput{
-					Request:     req,
-					PathParams:  pathParams(c),
-					QueryParams: c.QueryParams(),
-					Route: &routers.Route{
-						Spec:      spec,
-						Path:      req.URL.Path,
-						PathItem:  pathItem,
-						Method:    req.Method,
-						Operation: operation,
-					},
-					Options: &openapi3filter.Options{
-						AuthenticationFunc: openapi3filter.NoopAuthenticationFunc,
-					},
-				})
-			if err != nil {
-				switch validateErr := err.(type) { //nolint:errorlint //it's ok
+			input := &openapi3filter.RequestValidationInput{
+				Request:     req,
+				PathParams:  pathParams(c),
+				QueryParams: c.QueryParams(),
+				Route: &routers.Route{
+					Spec:      spec,
+					Path:      req.URL.Path,
+					PathItem:  pathItem,
+					Method:    req.Method,
+					Operation: operation,
+				},
+				Options: &openapi3filter.Options{
+					AuthenticationFunc: config.Authentication,
+				},
+			}
+
+			var principal *Principal
+			if err := openapi3filter.ValidateRequest(context.WithValue(req.Context(), principalKey{}, &principal), input); err != nil {
+				switch err := err.(type) { //nolint:errorlint //it's ok
 				case *openapi3filter.RequestError:
-					return reportErr(c, http.StatusBadRequest, validateErr.Error())
+					return reportErr(c, http.StatusBadRequest, err)
 				case *openapi3filter.SecurityRequirementsError:
-					return reportErr(c, http.StatusForbidden, validateErr.Error())
+					return reportErr(c, securityStatus(err), err)
 				default:
-					return reportErr(c, http.StatusInternalServerError, err.Error())
+					return reportErr(c, http.StatusInternalServerError, err)
 				}
 			}
+			if principal != nil {
+				c.Set(PrincipalKey, principal)
+			}
+
+			if config.sampled() {
+				return config.validateResponse(c, input, nextHandler)
+			}
+
 			return nextHandler(c)
 		}
 	}
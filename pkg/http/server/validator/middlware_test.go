@@ -1,16 +1,22 @@
 package validator_test
 
 import (
+	"bufio"
 	"bytes"
 	"encoding/json"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 
 	"github.com/getkin/kin-openapi/openapi3"
 	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
 	"github.com/matryer/is"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 
+	"github.com/fusionmedialimited/backend-infra-libraries/v3/pkg/http/server/problem"
 	"github.com/fusionmedialimited/backend-infra-libraries/v3/pkg/http/server/validator"
 )
 
@@ -181,3 +187,162 @@ func TestBody(t *testing.T) {
 		})
 	}
 }
+
+func TestProblem(t *testing.T) {
+	var (
+		isLib = is.New(t)
+		e     = newServer(isLib)
+	)
+
+	data, err := json.Marshal(map[string]any{
+		"name":           "My Test Watchlist",
+		"currency_id":    1,
+		"source":         "xxxxxxxx",
+		"instrument_ids": []int64{1},
+	})
+	isLib.NoErr(err)
+
+	req := httptest.NewRequest(http.MethodPost, host+"/v1/watchlist", bytes.NewReader(data))
+	req.Header.Set("X-User-Id", "1")
+	req.Header.Set("Content-Type", "application/json")
+	res := httptest.NewRecorder()
+	e.ServeHTTP(res, req)
+
+	var p problem.Problem
+	isLib.NoErr(json.Unmarshal(res.Body.Bytes(), &p))
+	isLib.Equal(problem.MIMEApplicationProblem, res.Header().Get("Content-Type"))
+	isLib.Equal(http.StatusBadRequest, p.Status)
+	isLib.Equal("/v1/watchlist", p.Instance)
+	isLib.Equal(1, len(p.Errors))
+	isLib.Equal("body/source", p.Errors[0].Field)
+}
+
+func TestResponseValidation(t *testing.T) {
+	isLib := is.New(t)
+
+	spec, err := (&openapi3.Loader{ReadFromURIFunc: openapi3.ReadFromFile}).LoadFromFile("test-spec.yaml")
+	isLib.NoErr(err)
+
+	for _, tc := range []struct {
+		mode         validator.ResponseMode
+		expectedCode int
+	}{
+		{mode: validator.ResponseReport, expectedCode: http.StatusOK},
+		{mode: validator.ResponseEnforce, expectedCode: http.StatusInternalServerError},
+	} {
+		violations := prometheus.NewCounterVec(prometheus.CounterOpts{Name: "violations"}, []string{"method", "route"})
+
+		e := echo.New()
+		e.Use(validator.NewMiddlewareFunc(spec,
+			validator.WithResponseValidation(tc.mode, 1),
+			validator.WithViolationsCounter(violations),
+		))
+		e.GET("/v1/watchlist", func(c echo.Context) error {
+			c.Response().Header().Set("X-Handler", "1")
+			return c.JSON(http.StatusOK, []echo.Map{{"id": 1}})
+		})
+
+		req := httptest.NewRequest(http.MethodGet, host+"/v1/watchlist", nil)
+		req.Header.Set("X-User-Id", "1")
+		res := httptest.NewRecorder()
+		e.ServeHTTP(res, req)
+
+		isLib.Equal(tc.expectedCode, res.Code)
+		isLib.Equal(tc.mode == validator.ResponseReport, res.Header().Get("X-Handler") == "1")
+		isLib.Equal(1.0, testutil.ToFloat64(violations.WithLabelValues(http.MethodGet, "/v1/watchlist")))
+	}
+}
+
+func TestResponseValidationSkipped(t *testing.T) {
+	spec, err := (&openapi3.Loader{ReadFromURIFunc: openapi3.ReadFromFile}).LoadFromFile("test-spec.yaml")
+	is.New(t).NoErr(err)
+
+	for name, handler := range map[string]echo.HandlerFunc{
+		"streaming": func(c echo.Context) error {
+			c.Response().Header().Set(echo.HeaderContentType, "text/event-stream")
+			c.Response().WriteHeader(http.StatusOK)
+			_, _ = c.Response().Write([]byte("data: 1\n\n"))
+			c.Response().Flush()
+			return nil
+		},
+		"large": func(c echo.Context) error {
+			return c.Blob(http.StatusOK, echo.MIMEApplicationJSON, bytes.Repeat([]byte(" "), 11<<20))
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			isLib := is.New(t)
+			violations := prometheus.NewCounterVec(prometheus.CounterOpts{Name: "violations"}, []string{"method", "route"})
+
+			e := echo.New()
+			e.Use(validator.NewMiddlewareFunc(spec,
+				validator.WithResponseValidation(validator.ResponseEnforce, 1),
+				validator.WithViolationsCounter(violations),
+			))
+			e.GET("/v1/watchlist", handler)
+
+			req := httptest.NewRequest(http.MethodGet, host+"/v1/watchlist", nil)
+			req.Header.Set("X-User-Id", "1")
+			res := httptest.NewRecorder()
+			e.ServeHTTP(res, req)
+
+			isLib.Equal(http.StatusOK, res.Code)
+			isLib.True(res.Body.Len() > 0)
+			isLib.Equal(0.0, testutil.ToFloat64(violations.WithLabelValues(http.MethodGet, "/v1/watchlist")))
+		})
+	}
+}
+
+func TestResponseValidationPanic(t *testing.T) {
+	isLib := is.New(t)
+
+	spec, err := (&openapi3.Loader{ReadFromURIFunc: openapi3.ReadFromFile}).LoadFromFile("test-spec.yaml")
+	isLib.NoErr(err)
+
+	e := echo.New()
+	e.Use(middleware.RecoverWithConfig(middleware.RecoverConfig{DisablePrintStack: true}))
+	e.Use(validator.NewMiddlewareFunc(spec, validator.WithResponseValidation(validator.ResponseReport, 1)))
+	e.GET("/v1/watchlist", func(c echo.Context) error {
+		panic("handler failed")
+	})
+
+	req := httptest.NewRequest(http.MethodGet, host+"/v1/watchlist", nil)
+	req.Header.Set("X-User-Id", "1")
+	res := httptest.NewRecorder()
+	e.ServeHTTP(res, req)
+
+	isLib.Equal(http.StatusInternalServerError, res.Code)
+	isLib.True(res.Body.Len() > 0)
+}
+
+func TestResponseValidationHijack(t *testing.T) {
+	isLib := is.New(t)
+
+	spec, err := (&openapi3.Loader{ReadFromURIFunc: openapi3.ReadFromFile}).LoadFromFile("test-spec.yaml")
+	isLib.NoErr(err)
+
+	e := echo.New()
+	e.Use(validator.NewMiddlewareFunc(spec, validator.WithResponseValidation(validator.ResponseEnforce, 1)))
+	e.GET("/v1/watchlist", func(c echo.Context) error {
+		conn, rw, err := c.Response().Hijack()
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+
+		_, _ = rw.WriteString("HTTP/1.1 101 Switching Protocols\r\nConnection: Upgrade\r\nUpgrade: websocket\r\n\r\n")
+		return rw.Flush()
+	})
+	srv := httptest.NewServer(e)
+	defer srv.Close()
+
+	conn, err := net.Dial("tcp", srv.Listener.Addr().String())
+	isLib.NoErr(err)
+	defer conn.Close()
+
+	_, err = conn.Write([]byte("GET /v1/watchlist HTTP/1.1\r\nHost: test\r\nX-User-Id: 1\r\nConnection: Upgrade\r\nUpgrade: websocket\r\n\r\n"))
+	isLib.NoErr(err)
+
+	resp, err := http.ReadResponse(bufio.NewReader(conn), nil)
+	isLib.NoErr(err)
+	isLib.Equal(http.StatusSwitchingProtocols, resp.StatusCode)
+}
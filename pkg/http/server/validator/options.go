@@ -0,0 +1,56 @@
+package validator
+
+import (
+	"go.uber.org/zap"
+
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	ResponseOff     ResponseMode = "off"
+	ResponseReport  ResponseMode = "report"
+	ResponseEnforce ResponseMode = "enforce"
+)
+
+type (
+	ResponseMode string
+
+	Config struct {
+		Authentication openapi3filter.AuthenticationFunc
+		Responses      ResponseMode
+		SampleRatio    float64
+		Logger         *zap.Logger
+		Violations     *prometheus.CounterVec
+	}
+
+	OptionFunc = func(config *Config)
+)
+
+func defaultConfig() Config {
+	return Config{
+		Authentication: openapi3filter.NoopAuthenticationFunc,
+		Responses:      ResponseOff,
+		SampleRatio:    1,
+		Logger:         zap.NewNop(),
+	}
+}
+
+func WithResponseValidation(mode ResponseMode, sampleRatio float64) OptionFunc {
+	return func(config *Config) {
+		config.Responses = mode
+		config.SampleRatio = sampleRatio
+	}
+}
+
+func WithLogger(log *zap.Logger) OptionFunc {
+	return func(config *Config) {
+		config.Logger = log
+	}
+}
+
+func WithViolationsCounter(counter *prometheus.CounterVec) OptionFunc {
+	return func(config *Config) {
+		config.Violations = counter
+	}
+}
@@ -0,0 +1,158 @@
+package validator
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"math/rand"
+	"net"
+	"net/http"
+
+	"go.uber.org/zap"
+
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/labstack/echo/v4"
+
+	"github.com/fusionmedialimited/backend-infra-libraries/v3/pkg/http/server/problem"
+)
+
+const maxBufferedResponse = 10 << 20
+
+// bufferedResponse is passed through without validation once it's flushed or too large.
+type bufferedResponse struct {
+	original    http.ResponseWriter
+	header      http.Header
+	status      int
+	body        bytes.Buffer
+	passthrough bool
+}
+
+func (r *bufferedResponse) Header() http.Header {
+	return r.header
+}
+
+func (r *bufferedResponse) WriteHeader(status int) {
+	r.status = status
+}
+
+func (r *bufferedResponse) Write(data []byte) (int, error) {
+	if !r.passthrough && r.body.Len()+len(data) > maxBufferedResponse {
+		if err := r.pass(); err != nil {
+			return 0, err
+		}
+	}
+	if r.passthrough {
+		return r.original.Write(data)
+	}
+
+	return r.body.Write(data)
+}
+
+func (r *bufferedResponse) Flush() {
+	if !r.passthrough && r.pass() != nil {
+		return
+	}
+	if flusher, ok := r.original.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Hijack passes upgraded connections, e.g. websockets, through without validation.
+func (r *bufferedResponse) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := r.original.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("response writer doesn't support hijacking")
+	}
+
+	r.passthrough = true
+	r.copyHeader()
+
+	return hijacker.Hijack()
+}
+
+func (r *bufferedResponse) copyHeader() {
+	header := r.original.Header()
+	for key := range header {
+		delete(header, key)
+	}
+	for key, values := range r.header {
+		header[key] = values
+	}
+}
+
+func (r *bufferedResponse) pass() error {
+	r.passthrough = true
+	r.copyHeader()
+	r.original.WriteHeader(r.status)
+	_, err := r.original.Write(r.body.Bytes())
+	r.body.Reset()
+
+	return err
+}
+
+func (c Config) sampled() bool {
+	return c.Responses != ResponseOff && c.Responses != "" &&
+		(c.SampleRatio >= 1 || rand.Float64() < c.SampleRatio) //nolint:gosec // sampling doesn't need crypto
+}
+
+func (c Config) validateResponse(ctx echo.Context, input *openapi3filter.RequestValidationInput, next echo.HandlerFunc) error {
+	var (
+		resp     = ctx.Response()
+		original = resp.Writer
+		buffered = &bufferedResponse{original: original, header: make(http.Header), status: http.StatusOK}
+	)
+	for key, values := range original.Header() {
+		buffered.header[key] = values
+	}
+
+	// restored by defer, so panics recovered by outer middlewares are written to the client
+	resp.Writer = buffered
+	err := func() error {
+		defer func() { resp.Writer = original }()
+		return next(ctx)
+	}()
+
+	if buffered.passthrough {
+		return err
+	}
+	if !resp.Committed {
+		buffered.copyHeader()
+		return err
+	}
+	if err != nil {
+		_ = buffered.pass()
+		return err
+	}
+
+	validateErr := openapi3filter.ValidateResponse(input.Request.Context(), (&openapi3filter.ResponseValidationInput{
+		RequestValidationInput: input,
+		Status:                 buffered.status,
+		Header:                 buffered.header,
+		Options:                &openapi3filter.Options{IncludeResponseStatus: true},
+	}).SetBodyBytes(buffered.body.Bytes()))
+	if validateErr == nil {
+		return buffered.pass()
+	}
+
+	route := ctx.Path()
+	if c.Violations != nil {
+		c.Violations.WithLabelValues(input.Request.Method, route).Inc()
+	}
+	c.Logger.Warn("Response violates OpenAPI spec",
+		zap.String("method", input.Request.Method), zap.String("route", route), zap.Error(validateErr))
+
+	if c.Responses != ResponseEnforce {
+		return buffered.pass()
+	}
+
+	// headers of the invalid response aren't flushed, so the problem is written from scratch
+	resp.Committed, resp.Size = false, 0
+
+	p := problem.New(http.StatusInternalServerError, "response doesn't match the contract")
+	var responseErr *openapi3filter.ResponseError
+	if errors.As(validateErr, &responseErr) && responseErr.Reason != "" {
+		p.Detail += ": " + responseErr.Reason
+	}
+
+	return problem.Write(ctx, p)
+}
@@ -0,0 +1,85 @@
+package signature
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	HeaderSignature = "X-Signature"
+	// MaxBody limits bodies buffered for signing and verification by default.
+	MaxBody = 10 << 20
+)
+
+var ErrInvalidSignature = errors.New("invalid request signature")
+
+// HMACSigner signs method, path, Date header and SHA-256 of body by HMAC-SHA256.
+type HMACSigner struct {
+	KeyID string
+	Key   []byte
+}
+
+func (s HMACSigner) Sign(r *http.Request, body []byte) error {
+	if r.Header.Get("Date") == "" {
+		r.Header.Set("Date", time.Now().UTC().Format(http.TimeFormat))
+	}
+
+	signature := signHMAC(s.Key, r, body)
+	r.Header.Set(HeaderSignature, fmt.Sprintf("keyId=%s,algorithm=hmac-sha256,signature=%s", s.KeyID, signature))
+
+	return nil
+}
+
+// VerifyHMAC checks the signature made by HMACSigner, Date header must be within skew from now.
+func VerifyHMAC(r *http.Request, body []byte, keys func(keyID string) ([]byte, bool), skew time.Duration) error {
+	params := make(map[string]string)
+	for _, param := range strings.Split(r.Header.Get(HeaderSignature), ",") {
+		key, val, _ := strings.Cut(param, "=")
+		params[strings.TrimSpace(key)] = strings.TrimSpace(val)
+	}
+
+	if params["algorithm"] != "hmac-sha256" {
+		return fmt.Errorf("%w: unsupported algorithm %q", ErrInvalidSignature, params["algorithm"])
+	}
+
+	key, ok := keys(params["keyId"])
+	if !ok {
+		return fmt.Errorf("%w: unknown key %q", ErrInvalidSignature, params["keyId"])
+	}
+
+	date, err := http.ParseTime(r.Header.Get("Date"))
+	if err != nil {
+		return fmt.Errorf("%w: date: %w", ErrInvalidSignature, err)
+	}
+
+	if diff := time.Since(date); diff > skew || diff < -skew {
+		return fmt.Errorf("%w: date is out of %s", ErrInvalidSignature, skew)
+	}
+
+	if !hmac.Equal([]byte(signHMAC(key, r, body)), []byte(params["signature"])) {
+		return fmt.Errorf("%w: signature mismatch", ErrInvalidSignature)
+	}
+
+	return nil
+}
+
+func signHMAC(key []byte, r *http.Request, body []byte) string {
+	bodySum := sha256.Sum256(body)
+	mac := hmac.New(sha256.New, key)
+	_, _ = io.WriteString(mac, strings.Join([]string{
+		r.Method,
+		r.URL.RequestURI(),
+		r.Header.Get("Date"),
+		hex.EncodeToString(bodySum[:]),
+	}, "\n"))
+
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,28 @@
+package primitives
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"time"
+)
+
+type WatchFn func(data []byte, err error)
+
+// WatchFile polls the file, since symlink swaps of mounted kubernetes secrets aren't seen by inotify.
+func WatchFile(ctx context.Context, path string, d time.Duration, fn WatchFn) error {
+	var last []byte
+
+	return NewTimer(d, func(context.Context) (bool, error) {
+		data, err := os.ReadFile(path) //nolint:gosec // path is provided by operator
+		switch {
+		case err != nil:
+			fn(nil, err)
+		case last == nil || !bytes.Equal(data, last):
+			last = data
+			fn(data, nil)
+		}
+
+		return false, nil
+	}).RunEach(ctx)
+}
@@ -5,6 +5,7 @@ import (
 	"strings"
 
 	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
 
 	"github.com/jmoiron/sqlx"
 
@@ -22,9 +23,11 @@ func Setup(c *di.Container) {
 		CommentsReplica,
 	} {
 		db := db
+		config.Register(Config{}, db)
 		di.SetNamed(c, db, di.OptInit(func() (Config, error) {
 			conf, err := ConfigFromEnv(db)
 			conf.OTELTraceProvider = di.Get[trace.TracerProvider](c)
+			conf.Logger = di.Get[*zap.Logger](c)
 			return conf, err
 		}))
 		di.SetNamed(c, db, di.OptInit(func() (*sqlx.DB, error) {
@@ -7,17 +7,17 @@ import (
 	"strings"
 	"time"
 
-	// mysql driver.
-	_ "github.com/go-sql-driver/mysql"
-
 	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
 
-	env "github.com/caarlos0/env/v6"
+	drv "github.com/go-sql-driver/mysql"
 	"github.com/jmoiron/sqlx"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/collectors"
 	"github.com/uptrace/opentelemetry-go-extra/otelsql"
-	"github.com/uptrace/opentelemetry-go-extra/otelsqlx"
+
+	"github.com/fusionmedialimited/backend-infra-libraries/v3/pkg/config"
+	"github.com/fusionmedialimited/backend-infra-libraries/v3/pkg/storage/rotation"
 )
 
 const (
@@ -32,29 +32,48 @@ const (
 type (
 	Kind   = string
 	Config struct {
-		User              string               `env:"MYSQL_USER"          envDefault:"root"`
-		Password          string               `env:"MYSQL_PASSWORD"      envDefault:"toor"`
-		Host              string               `env:"MYSQL_HOST"          envDefault:"db"`
-		Port              int                  `env:"MYSQL_PORT"          envDefault:"3306"`
-		Database          string               `env:"MYSQL_DATABASE"`
-		Options           []string             `env:"MYSQL_OPTIONS"       envDefault:"charset=utf8&parseTime=True" envSeparator:"&"`
-		MaxIdleTime       time.Duration        `env:"MYSQL_MAX_IDLE_TIME" envDefault:"60s"`
-		MaxLifetime       time.Duration        `env:"MYSQL_MAX_LIFETIME"  envDefault:"5m"`
-		MaxTotal          int                  `env:"MYSQL_MAX_TOTAL"     envDefault:"32"`
-		MaxIdle           int                  `env:"MYSQL_MAX_IDLE"      envDefault:"8"`
+		User              string               `env:"MYSQL_USER"             envDefault:"root"  validate:"required" desc:"Database user"`
+		Password          string               `env:"MYSQL_PASSWORD"         envDefault:"toor"  secret:"true" desc:"Database password"`
+		PasswordFile      string               `env:"MYSQL_PASSWORD_FILE" desc:"File to read and watch the password from"`
+		PasswordRefresh   time.Duration        `env:"MYSQL_PASSWORD_REFRESH" envDefault:"30s"   validate:"min=1s" desc:"Poll period of the password file"`
+		Host              string               `env:"MYSQL_HOST"             envDefault:"db"    validate:"required" desc:"Database host"`
+		Port              int                  `env:"MYSQL_PORT"             envDefault:"3306"  validate:"min=1,max=65535" desc:"Database port"`
+		Database          string               `env:"MYSQL_DATABASE" desc:"Database name"`
+		Options           []string             `env:"MYSQL_OPTIONS"          envDefault:"charset=utf8&parseTime=True" envSeparator:"&" desc:"Driver DSN options"`
+		MaxIdleTime       time.Duration        `env:"MYSQL_MAX_IDLE_TIME"    envDefault:"60s"   validate:"min=0s" desc:"Max idle time of a connection"`
+		MaxLifetime       time.Duration        `env:"MYSQL_MAX_LIFETIME"     envDefault:"5m"    validate:"min=0s" desc:"Max lifetime of a connection"`
+		MaxTotal          int                  `env:"MYSQL_MAX_TOTAL"        envDefault:"32"    validate:"min=0" desc:"Max open connections, 0 is unlimited"`
+		MaxIdle           int                  `env:"MYSQL_MAX_IDLE"         envDefault:"8"     validate:"min=0" desc:"Max idle connections"`
 		OTELTraceProvider trace.TracerProvider `env:"-"`
+		Logger            *zap.Logger          `env:"-"`
 	}
 )
 
 func ConfigFromEnv(kind Kind) (conf Config, _ error) {
-	var (
-		opts = env.Options{Prefix: kind}
-		err  = env.Parse(&conf, opts)
-	)
-
+	err := config.Parse(&conf, kind)
 	return conf, err
 }
 
+//nolint:gocritic // huge param is ok since called only once during bootstrap
+func (c Config) Validate() error {
+	if c.MaxTotal > 0 && c.MaxIdle > c.MaxTotal {
+		return config.NewFieldError("MaxIdle", fmt.Errorf("must be <= max total %d, got %d", c.MaxTotal, c.MaxIdle))
+	}
+
+	return nil
+}
+
+//nolint:gocritic // huge param is ok for the rare printing
+func (c Config) String() string {
+	type plain Config
+	redacted, err := config.Redacted(c)
+	if err != nil {
+		return err.Error()
+	}
+
+	return fmt.Sprintf("%+v", plain(redacted))
+}
+
 func makeDSN(user, password, host string, port int, database string, options []string) string {
 	var opts string
 	if len(options) > 0 {
@@ -72,13 +91,14 @@ func makeDSN(user, password, host string, port int, database string, options []s
 }
 
 //nolint:gocritic // huge param is ok since called only once during bootstrap
-func NewDB(ctx context.Context, name string, conf Config) (db *sqlx.DB, err error) {
-	if db, err = otelsqlx.ConnectContext(
-		ctx,
-		"mysql",
-		makeDSN(conf.User, conf.Password, conf.Host, conf.Port, conf.Database, conf.Options),
-		otelsql.WithTracerProvider(conf.OTELTraceProvider),
-	); err != nil {
+func NewDB(ctx context.Context, name string, conf Config) (*sqlx.DB, error) {
+	connector := rotation.NewConnector(drv.MySQLDriver{}, func(password string) string {
+		return makeDSN(conf.User, password, conf.Host, conf.Port, conf.Database, conf.Options)
+	}, conf.Password)
+
+	db := sqlx.NewDb(otelsql.OpenDB(connector, otelsql.WithTracerProvider(conf.OTELTraceProvider)), "mysql")
+	if err := db.PingContext(ctx); err != nil {
+		_ = db.Close()
 		dsn := makeDSN(conf.User, "***", conf.Host, conf.Port, conf.Database, conf.Options)
 		return nil, fmt.Errorf("failed to ping %s: %w", dsn, err)
 	}
@@ -90,5 +110,24 @@ func NewDB(ctx context.Context, name string, conf Config) (db *sqlx.DB, err erro
 
 	prometheus.MustRegister(collectors.NewDBStatsCollector(db.DB, name))
 
+	if conf.PasswordFile != "" {
+		logger := conf.Logger
+		if logger == nil {
+			logger = zap.NewNop()
+		}
+
+		if err := rotation.Watch(ctx, db.DB, connector, rotation.WatchConfig{
+			Name:       name,
+			Path:       conf.PasswordFile,
+			Period:     conf.PasswordRefresh,
+			MaxIdle:    conf.MaxIdle,
+			Logger:     logger,
+			Registerer: prometheus.DefaultRegisterer,
+		}); err != nil {
+			_ = db.Close()
+			return nil, err
+		}
+	}
+
 	return db, nil
 }
@@ -1,9 +1,13 @@
 package mysql
 
 import (
+	"errors"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
+	"github.com/fusionmedialimited/backend-infra-libraries/v3/pkg/config"
 	"github.com/fusionmedialimited/backend-infra-libraries/v3/pkg/primitives"
 )
 
@@ -92,3 +96,72 @@ func Test_makeDSN(t *testing.T) {
 		})
 	}
 }
+
+func TestConfigFromEnvSecrets(t *testing.T) {
+	secretPath := filepath.Join(t.TempDir(), "password")
+	if err := os.WriteFile(secretPath, []byte("from-file\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		envs     map[string]string
+		password string
+		err      error
+	}{
+		{
+			"default",
+			map[string]string{},
+			"toor",
+			nil,
+		},
+		{
+			"from file",
+			map[string]string{"TEST_MYSQL_PASSWORD_FILE": secretPath},
+			"from-file",
+			nil,
+		},
+		{
+			"both value and file",
+			map[string]string{"TEST_MYSQL_PASSWORD": "secret", "TEST_MYSQL_PASSWORD_FILE": secretPath},
+			"",
+			config.ErrAmbiguousSecret,
+		},
+		{
+			"default in production",
+			map[string]string{config.EnvAppEnv: config.AppEnvProduct},
+			"",
+			config.ErrInsecureDefault,
+		},
+		{
+			"explicit in production",
+			map[string]string{config.EnvAppEnv: config.AppEnvProduct, "TEST_MYSQL_PASSWORD": "secret"},
+			"secret",
+			nil,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt // pin
+		t.Run(tt.name, func(t *testing.T) {
+			closer := envSetter(tt.envs)
+			defer closer()
+
+			conf, err := ConfigFromEnv("TEST_")
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("ConfigFromEnv() error = %v, want %v", err, tt.err)
+			}
+			if err != nil {
+				if strings.Contains(err.Error(), "from-file") || strings.Contains(err.Error(), "secret") {
+					t.Errorf("ConfigFromEnv() error reveals secret: %v", err)
+				}
+				return
+			}
+			if conf.Password != tt.password {
+				t.Errorf("Password = %v, want %v", conf.Password, tt.password)
+			}
+			if strings.Contains(conf.String(), tt.password) {
+				t.Errorf("String() reveals secret: %v", conf)
+			}
+		})
+	}
+}
@@ -3,6 +3,8 @@ package postgres
 import (
 	"context"
 
+	"go.uber.org/zap"
+
 	"github.com/jmoiron/sqlx"
 
 	"github.com/fusionmedialimited/backend-infra-libraries/v3/pkg/config"
@@ -10,8 +12,11 @@ import (
 )
 
 func Setup(c *di.Container) {
+	config.Register(Config{}, Custom)
 	di.Set(c, di.OptInit(func() (Config, error) {
-		return ConfigFromEnv(Custom)
+		conf, err := ConfigFromEnv(Custom)
+		conf.Logger = di.Get[*zap.Logger](c)
+		return conf, err
 	}))
 	di.Set(c, di.OptInit(func() (*sqlx.DB, error) {
 		return NewDB(
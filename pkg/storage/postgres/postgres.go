@@ -2,17 +2,20 @@ package postgres
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
 	"strings"
 	"time"
 
-	// postgresql driver.
-	_ "github.com/lib/pq"
+	"go.uber.org/zap"
 
-	env "github.com/caarlos0/env/v6"
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/collectors"
+
+	"github.com/fusionmedialimited/backend-infra-libraries/v3/pkg/config"
+	"github.com/fusionmedialimited/backend-infra-libraries/v3/pkg/storage/rotation"
 )
 
 const (
@@ -22,27 +25,47 @@ const (
 type (
 	Kind   = string
 	Config struct {
-		User        string        `env:"POSTGRES_USER"          envDefault:"postgres"`
-		Password    string        `env:"POSTGRES_PASSWORD"      envDefault:"postgres"`
-		Host        string        `env:"POSTGRES_HOST"          envDefault:"db"`
-		Port        int           `env:"POSTGRES_PORT"          envDefault:"5432"`
-		Database    string        `env:"POSTGRES_DATABASE"`
-		Options     []string      `env:"POSTGRES_OPTIONS"       envDefault:"sslmode=disable" envSeparator:" "`
-		MaxIdleTime time.Duration `env:"POSTGRES_MAX_IDLE_TIME" envDefault:"60s"`
-		MaxLifetime time.Duration `env:"POSTGRES_MAX_LIFETIME"  envDefault:"5m"`
-		MaxTotal    int           `env:"POSTGRES_MAX_TOTAL"     envDefault:"32"`
-		MaxIdle     int           `env:"POSTGRES_MAX_IDLE"      envDefault:"8"`
+		User            string        `env:"POSTGRES_USER"             envDefault:"postgres" validate:"required" desc:"Database user"`
+		Password        string        `env:"POSTGRES_PASSWORD"         envDefault:"postgres" secret:"true" desc:"Database password"`
+		PasswordFile    string        `env:"POSTGRES_PASSWORD_FILE" desc:"File to read and watch the password from"`
+		PasswordRefresh time.Duration `env:"POSTGRES_PASSWORD_REFRESH" envDefault:"30s"      validate:"min=1s" desc:"Poll period of the password file"`
+		Host            string        `env:"POSTGRES_HOST"             envDefault:"db"       validate:"required" desc:"Database host"`
+		Port            int           `env:"POSTGRES_PORT"             envDefault:"5432"     validate:"min=1,max=65535" desc:"Database port"`
+		Database        string        `env:"POSTGRES_DATABASE"                               validate:"required" desc:"Database name"`
+		Options         []string      `env:"POSTGRES_OPTIONS"          envDefault:"sslmode=disable" envSeparator:" " desc:"Driver DSN options"`
+		MaxIdleTime     time.Duration `env:"POSTGRES_MAX_IDLE_TIME"    envDefault:"60s"      validate:"min=0s" desc:"Max idle time of a connection"`
+		MaxLifetime     time.Duration `env:"POSTGRES_MAX_LIFETIME"     envDefault:"5m"       validate:"min=0s" desc:"Max lifetime of a connection"`
+		MaxTotal        int           `env:"POSTGRES_MAX_TOTAL"        envDefault:"32"       validate:"min=0" desc:"Max open connections, 0 is unlimited"`
+		MaxIdle         int           `env:"POSTGRES_MAX_IDLE"         envDefault:"8"        validate:"min=0" desc:"Max idle connections"`
+		Logger          *zap.Logger   `env:"-"`
 	}
 )
 
 func ConfigFromEnv(kind Kind) (conf Config, _ error) {
-	var (
-		opts = env.Options{Prefix: kind}
-		err  = env.Parse(&conf, opts)
-	)
+	err := config.Parse(&conf, kind)
 	return conf, err
 }
 
+//nolint:gocritic // huge param is ok since called only once during bootstrap
+func (c Config) Validate() error {
+	if c.MaxTotal > 0 && c.MaxIdle > c.MaxTotal {
+		return config.NewFieldError("MaxIdle", fmt.Errorf("must be <= max total %d, got %d", c.MaxTotal, c.MaxIdle))
+	}
+
+	return nil
+}
+
+//nolint:gocritic // huge param is ok for the rare printing
+func (c Config) String() string {
+	type plain Config
+	redacted, err := config.Redacted(c)
+	if err != nil {
+		return err.Error()
+	}
+
+	return fmt.Sprintf("%+v", plain(redacted))
+}
+
 func makeDSN(user, password, host string, port int, database string, options []string) string {
 	var dsn = fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s",
 		host, port, user, password, database)
@@ -55,24 +78,42 @@ func makeDSN(user, password, host string, port int, database string, options []s
 
 //nolint:gocritic // huge param is ok since called only once during bootstrap
 func NewDB(ctx context.Context, name string, conf Config) (db *sqlx.DB, err error) {
-	dsn := makeDSN(conf.User, conf.Password, conf.Host, conf.Port, conf.Database, conf.Options)
-	db, err = sqlx.Open("postgres", dsn)
-	if err != nil {
-		dsn = makeDSN(conf.User, "***", conf.Host, conf.Port, conf.Database, conf.Options)
-		return nil, fmt.Errorf("failed to connect %s: %w", dsn, err)
-	}
+	connector := rotation.NewConnector(pq.Driver{}, func(password string) string {
+		return makeDSN(conf.User, password, conf.Host, conf.Port, conf.Database, conf.Options)
+	}, conf.Password)
 
+	db = sqlx.NewDb(sql.OpenDB(connector), "postgres")
 	db.SetConnMaxIdleTime(conf.MaxIdleTime)
 	db.SetConnMaxLifetime(conf.MaxLifetime)
 	db.SetMaxOpenConns(conf.MaxTotal)
 	db.SetMaxIdleConns(conf.MaxIdle)
 
 	if err = db.PingContext(ctx); err != nil {
-		dsn = makeDSN(conf.User, "***", conf.Host, conf.Port, conf.Database, conf.Options)
+		_ = db.Close()
+		dsn := makeDSN(conf.User, "***", conf.Host, conf.Port, conf.Database, conf.Options)
 		return nil, fmt.Errorf("failed to ping %s: %w", dsn, err)
 	}
 
 	prometheus.MustRegister(collectors.NewDBStatsCollector(db.DB, name))
 
+	if conf.PasswordFile != "" {
+		logger := conf.Logger
+		if logger == nil {
+			logger = zap.NewNop()
+		}
+
+		if err = rotation.Watch(ctx, db.DB, connector, rotation.WatchConfig{
+			Name:       name,
+			Path:       conf.PasswordFile,
+			Period:     conf.PasswordRefresh,
+			MaxIdle:    conf.MaxIdle,
+			Logger:     logger,
+			Registerer: prometheus.DefaultRegisterer,
+		}); err != nil {
+			_ = db.Close()
+			return nil, err
+		}
+	}
+
 	return db, nil
 }
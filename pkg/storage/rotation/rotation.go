@@ -0,0 +1,109 @@
+package rotation
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/fusionmedialimited/backend-infra-libraries/v3/pkg/primitives"
+)
+
+type (
+	DSNFunc func(password string) string
+
+	// Connector opens connections with the current password.
+	Connector struct {
+		driver   driver.Driver
+		dsn      DSNFunc
+		password atomic.Pointer[string]
+	}
+
+	WatchConfig struct {
+		Name       string
+		Path       string
+		Period     time.Duration
+		MaxIdle    int
+		Logger     *zap.Logger
+		Registerer prometheus.Registerer
+	}
+)
+
+var _ driver.Connector = &Connector{}
+
+func NewConnector(drv driver.Driver, dsn DSNFunc, password string) *Connector {
+	c := &Connector{driver: drv, dsn: dsn}
+	c.password.Store(&password)
+
+	return c
+}
+
+func (c *Connector) Connect(ctx context.Context) (driver.Conn, error) {
+	dsn := c.dsn(*c.password.Load())
+
+	if drv, ok := c.driver.(driver.DriverContext); ok {
+		connector, err := drv.OpenConnector(dsn)
+		if err != nil {
+			return nil, err
+		}
+
+		return connector.Connect(ctx)
+	}
+
+	return c.driver.Open(dsn)
+}
+
+func (c *Connector) Driver() driver.Driver {
+	return c.driver
+}
+
+func (c *Connector) Rotate(password string) bool {
+	return *c.password.Swap(&password) != password
+}
+
+// Watch rotates the password on change of the file, busy connections are recycled by ConnMaxLifetime.
+//
+//nolint:gocritic // huge param is ok since called only once during bootstrap
+func Watch(ctx context.Context, db *sql.DB, connector *Connector, conf WatchConfig) error {
+	rotations := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name:        "go_sql_secret_rotations_total",
+		Help:        "The total number of secret file changes.",
+		ConstLabels: prometheus.Labels{"db_name": conf.Name},
+	}, []string{"result"})
+	if err := conf.Registerer.Register(rotations); err != nil {
+		return err
+	}
+
+	logger := conf.Logger.With(zap.String("db_name", conf.Name), zap.String("path", conf.Path))
+	go func() {
+		err := primitives.WatchFile(ctx, conf.Path, conf.Period, func(data []byte, err error) {
+			if err != nil {
+				rotations.WithLabelValues("error").Inc()
+				logger.Warn("Cannot read secret file", zap.Error(err))
+				return
+			}
+
+			if !connector.Rotate(strings.TrimRight(string(data), "\r\n")) {
+				return
+			}
+
+			db.SetMaxIdleConns(0) // drops idle connections with old credentials
+			db.SetMaxIdleConns(conf.MaxIdle)
+
+			rotations.WithLabelValues("ok").Inc()
+			logger.Info("Secret rotated")
+		})
+		if err != nil && !errors.Is(err, context.Canceled) {
+			logger.Error("Secret watcher stopped", zap.Error(err))
+		}
+	}()
+
+	return nil
+}
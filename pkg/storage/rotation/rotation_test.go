@@ -0,0 +1,104 @@
+package rotation
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type (
+	fakeDriver struct {
+		mu   sync.Mutex
+		dsns []string
+	}
+	fakeConn struct{}
+)
+
+func (d *fakeDriver) Open(dsn string) (driver.Conn, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.dsns = append(d.dsns, dsn)
+
+	return fakeConn{}, nil
+}
+
+func (d *fakeDriver) last() string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return d.dsns[len(d.dsns)-1]
+}
+
+func (fakeConn) Prepare(string) (driver.Stmt, error) { return nil, errors.New("not implemented") }
+func (fakeConn) Close() error                        { return nil }
+func (fakeConn) Begin() (driver.Tx, error)           { return nil, errors.New("not implemented") }
+
+func TestRotate(t *testing.T) {
+	connector := NewConnector(&fakeDriver{}, func(password string) string { return password }, "old")
+
+	if connector.Rotate("old") {
+		t.Errorf("Rotate() reports change of the same password")
+	}
+	if !connector.Rotate("new") {
+		t.Errorf("Rotate() doesn't report change")
+	}
+}
+
+func TestWatch(t *testing.T) {
+	var (
+		drv         = &fakeDriver{}
+		path        = filepath.Join(t.TempDir(), "password")
+		ctx, cancel = context.WithCancel(context.Background())
+	)
+	defer cancel()
+
+	if err := os.WriteFile(path, []byte("old\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	connector := NewConnector(drv, func(password string) string { return "user:" + password }, "old")
+	db := sql.OpenDB(connector)
+	defer db.Close()
+
+	if err := db.PingContext(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	err := Watch(ctx, db, connector, WatchConfig{
+		Name:       "test",
+		Path:       path,
+		Period:     time.Millisecond,
+		MaxIdle:    1,
+		Logger:     zap.NewNop(),
+		Registerer: prometheus.NewRegistry(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = os.WriteFile(path, []byte("new\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for drv.last() != "user:new" {
+		if time.Now().After(deadline) {
+			t.Fatalf("connection with new password isn't opened, last dsn: %s", drv.last())
+		}
+
+		time.Sleep(time.Millisecond)
+		if err = db.PingContext(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,38 @@
+package tracing
+
+import (
+	"sync/atomic"
+
+	sdk "go.opentelemetry.io/otel/sdk/trace"
+)
+
+type (
+	// Sampler is a trace ID ratio based sampler, which ratio may be changed at runtime.
+	Sampler struct {
+		current atomic.Pointer[ratioSampler]
+	}
+	ratioSampler struct {
+		sdk.Sampler
+	}
+)
+
+var _ sdk.Sampler = &Sampler{}
+
+func NewSampler(ratio float64) *Sampler {
+	s := new(Sampler)
+	s.SetRatio(ratio)
+
+	return s
+}
+
+func (s *Sampler) SetRatio(ratio float64) {
+	s.current.Store(&ratioSampler{sdk.TraceIDRatioBased(ratio)})
+}
+
+func (s *Sampler) ShouldSample(p sdk.SamplingParameters) sdk.SamplingResult {
+	return s.current.Load().ShouldSample(p)
+}
+
+func (s *Sampler) Description() string {
+	return s.current.Load().Description()
+}
@@ -2,6 +2,7 @@ package tracing
 
 import (
 	"context"
+	"errors"
 
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
@@ -10,14 +11,25 @@ import (
 	sdk "go.opentelemetry.io/otel/sdk/trace"
 	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
 	"go.opentelemetry.io/otel/trace"
+
+	"github.com/fusionmedialimited/backend-infra-libraries/v3/pkg/config"
 )
 
 type Config struct {
-	Name    string  `env:"-"`
-	Version string  `env:"-"`
-	Enable  bool    `env:"OTEL_TRACING_ENABLE" envDefault:"true"`
-	URL     string  `env:"OTEL_TRACING_URL"    envDefault:"localhost:4317"`
-	Ratio   float64 `env:"OTEL_TRACING_RATIO"  envDefault:"1"`
+	Name    string   `env:"-"`
+	Version string   `env:"-"`
+	Enable  bool     `env:"OTEL_TRACING_ENABLE" envDefault:"true" desc:"Enables OpenTelemetry tracing"`
+	URL     string   `env:"OTEL_TRACING_URL"    envDefault:"localhost:4317" desc:"OTLP gRPC collector endpoint"`
+	Ratio   float64  `env:"OTEL_TRACING_RATIO"  envDefault:"1" validate:"min=0,max=1" desc:"Sampling ratio of root spans, 0..1, it's applied live on reload"`
+	Sampler *Sampler `env:"-" json:"-"` // created by Ratio if it's nil
+}
+
+func (c Config) Validate() error {
+	if c.Enable && c.URL == "" {
+		return config.NewFieldError("URL", errors.New("is required when tracing is enabled"))
+	}
+
+	return nil
 }
 
 func New(ctx context.Context, config Config) (trace.TracerProvider, error) {
@@ -34,9 +46,14 @@ func New(ctx context.Context, config Config) (trace.TracerProvider, error) {
 		return nil, err
 	}
 
+	sampler := config.Sampler
+	if sampler == nil {
+		sampler = NewSampler(config.Ratio)
+	}
+
 	traceProvider := sdk.NewTracerProvider(
 		sdk.WithBatcher(exporter),
-		sdk.WithSampler(sdk.ParentBased(sdk.TraceIDRatioBased(config.Ratio))), // https://opentelemetry.io/docs/instrumentation/go/sampling/
+		sdk.WithSampler(sdk.ParentBased(sampler)), // https://opentelemetry.io/docs/instrumentation/go/sampling/
 		sdk.WithResource(resource.NewWithAttributes(
 			semconv.SchemaURL,
 			semconv.ServiceNameKey.String(config.Name),